@@ -0,0 +1,127 @@
+package githubstats
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// accessLogger Write one Combined Log Format line per request, shared by every endpoint.
+type accessLogger struct {
+	mu     sync.Mutex
+	out    io.Writer
+	redact []byte                       // Key hashing the usernames of the query string (not redacted if nil)
+	ip     func(r *http.Request) string // Client IP address, looking through the trusted proxies
+}
+
+type accessLogHandler struct {
+	next   http.Handler
+	logger *accessLogger
+}
+
+// statusRecorder Record the status code and the body size written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+// WriteHeader Record the status code.
+/*
+ * @param status int - The status code
+ * @return void
+ */
+func (sr *statusRecorder) WriteHeader(status int) {
+	if sr.status == 0 {
+		sr.status = status
+	}
+	sr.ResponseWriter.WriteHeader(status)
+}
+
+// Write Record the number of bytes written.
+/*
+ * @param b []byte - The data
+ * @return int, error - The number of bytes written, the error
+ */
+func (sr *statusRecorder) Write(b []byte) (int, error) {
+	if sr.status == 0 {
+		sr.status = http.StatusOK
+	}
+	n, err := sr.ResponseWriter.Write(b)
+	sr.bytes += n
+	return n, err
+}
+
+// newAccessLogger Create a new access logger.
+/*
+ * @param out io.Writer - The log output
+ * @param redact []byte - The key hashing the usernames of the query string (not redacted if nil)
+ * @param ip func(r *http.Request) string - The client IP address extractor
+ * @return *accessLogger - The logger
+ */
+func newAccessLogger(out io.Writer, redact []byte, ip func(r *http.Request) string) *accessLogger {
+	return &accessLogger{
+		out:    out,
+		redact: redact,
+		ip:     ip,
+	}
+}
+
+// wrap Wrap a handler to log its requests.
+/*
+ * @param next http.Handler - The handler
+ * @return *accessLogHandler - The handler
+ */
+func (l *accessLogger) wrap(next http.Handler) *accessLogHandler {
+	return &accessLogHandler{
+		next:   next,
+		logger: l,
+	}
+}
+
+// ServeHTTP Serve the request and write its log line.
+/*
+ * @param w http.ResponseWriter - The response writer
+ * @param r *http.Request - The request
+ * @return void
+ */
+func (h *accessLogHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	rec := &statusRecorder{ResponseWriter: w}
+	h.next.ServeHTTP(rec, r)
+
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+
+	host := h.logger.ip(r)
+
+	uri := r.URL.RequestURI()
+	if h.logger.redact != nil {
+		uri = redactRequestURI(h.logger.redact, r.URL)
+	}
+
+	size := "-"
+	if rec.bytes > 0 {
+		size = fmt.Sprint(rec.bytes)
+	}
+
+	// host ident authuser [time] "request" status bytes "referer" "user-agent" duration request-id
+	line := fmt.Sprintf("%s - - [%s] \"%s %s %s\" %d %s %q %q %dms %s\n",
+		host,
+		start.Format("02/Jan/2006:15:04:05 -0700"),
+		r.Method, uri, r.Proto,
+		rec.status,
+		size,
+		r.Referer(),
+		r.UserAgent(),
+		time.Since(start).Milliseconds(),
+		RequestID(r.Context()),
+	)
+
+	h.logger.mu.Lock()
+	defer h.logger.mu.Unlock()
+	io.WriteString(h.logger.out, line)
+}
@@ -0,0 +1,30 @@
+package githubstats
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+// TestAccessLogFormat Check that each request is logged as a Combined Log Format line.
+func TestAccessLogFormat(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	f.handleJSON("GET /users/octocat/repos", []interface{}{})
+	var out bytes.Buffer
+	g := newTestGStats(t, f, Config{AccessLog: &out})
+
+	r := httptest.NewRequest(http.MethodGet, "/stats?username=octocat", nil)
+	r.RemoteAddr = "192.0.2.1:1234"
+	r.Header.Set("Referer", "https://example.com/")
+	r.Header.Set("User-Agent", "test-agent")
+	r.Header.Set("X-Request-ID", "req-1")
+	serveRequest(g, r)
+
+	pattern := regexp.MustCompile(`^192\.0\.2\.1 - - \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] "GET /stats\?username=octocat HTTP/1\.1" 200 \d+ "https://example\.com/" "test-agent" \d+ms req-1\n$`)
+	if line := out.String(); !pattern.MatchString(line) {
+		t.Errorf("log line = %q, want the Combined Log Format", line)
+	}
+}
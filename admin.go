@@ -0,0 +1,237 @@
+package githubstats
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+type CacheKeyInfo struct {
+	Key        string    `json:"key"`
+	Expiration time.Time `json:"expiration"`
+}
+
+type WarmRequest struct {
+	Usernames []string `json:"usernames"`
+}
+
+type WarmResponse struct {
+	Warmed int               `json:"warmed"`
+	Failed map[string]string `json:"failed,omitempty"`
+}
+
+type QuotaInfo struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+}
+
+type TokenDiag struct {
+	Index  int        `json:"index"`           // Position of the token in the configuration, the token itself is never exposed
+	Login  string     `json:"login,omitempty"` // Owner of the token
+	Scopes []string   `json:"scopes"`          // OAuth scopes granted to the token
+	Core   *QuotaInfo `json:"core,omitempty"`
+	Search *QuotaInfo `json:"search,omitempty"`
+	Error  string     `json:"error,omitempty"` // Why the token could not be checked
+}
+
+// requireAdmin Wrap a handler so it is only served with the admin bearer token.
+/*
+ * @param next http.HandlerFunc - The handler
+ * @return http.HandlerFunc - The handler
+ */
+func (g *GStats) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(g.config.AdminToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, r, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// cacheHandler Handle the admin requests listing the cached keys and their expiration.
+/*
+ * @param w http.ResponseWriter - The response writer
+ * @param r *http.Request - The request
+ * @return void
+ */
+func (g *GStats) cacheHandler(w http.ResponseWriter, r *http.Request) {
+	entries := g.cache.Entries()
+	keys := make([]CacheKeyInfo, 0, len(entries))
+	for _, key := range g.cache.Keys() {
+		keys = append(keys, CacheKeyInfo{Key: key, Expiration: entries[key]})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(keys)
+}
+
+// snapshotHandler Handle the admin requests exporting the cache (GET) or importing a snapshot into it (POST).
+/*
+ * @param w http.ResponseWriter - The response writer
+ * @param r *http.Request - The request
+ * @return void
+ */
+func (g *GStats) snapshotHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(g.cache.Snapshot())
+	case http.MethodPost:
+		var snapshot map[string]CacheEntry
+		if !g.decodeJSONBody(w, r, &snapshot) {
+			return
+		}
+		g.cache.Restore(snapshot)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", http.MethodGet+", "+http.MethodPost)
+		writeError(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// warmHandler Handle the admin requests fetching and caching a list of users.
+/*
+ * The include options are read from the query string like on the stats path.
+ *
+ * @param w http.ResponseWriter - The response writer
+ * @param r *http.Request - The request
+ * @return void
+ */
+func (g *GStats) warmHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !g.checkStrictParams(w, r, true, warmParams) {
+		return
+	}
+
+	var req WarmRequest
+	if !g.decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	opts, err := g.parseIncludeOptions(r.URL.Query())
+	if err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validateIncludeOptions(opts); err != nil {
+		writeError(w, r, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	usernames := parseUsernames(strings.Join(req.Usernames, ","))
+	_, errs := g.fetchAll(r.Context(), usernames, opts)
+
+	resp := WarmResponse{}
+	for i, err := range errs {
+		if err != nil {
+			if resp.Failed == nil {
+				resp.Failed = make(map[string]string)
+			}
+			resp.Failed[usernames[i]] = err.Error()
+			continue
+		}
+		resp.Warmed++
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// rateLimitResetHandler Handle the admin requests resetting every rate limiter, the global one and those of EndpointRateLimits.
+/*
+ * Not rate limited itself, so it works while the limits are reached.
+ *
+ * @param w http.ResponseWriter - The response writer
+ * @param r *http.Request - The request
+ * @return void
+ */
+func (g *GStats) rateLimitResetHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	g.rateLimiter.Reset()
+	for _, limiter := range g.endpointLimiters {
+		limiter.Reset()
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// diagHandler Handle the admin requests checking each configured token and reporting its quota.
+/*
+ * @param w http.ResponseWriter - The response writer
+ * @param r *http.Request - The request
+ * @return void
+ */
+func (g *GStats) diagHandler(w http.ResponseWriter, r *http.Request) {
+	diags := make([]TokenDiag, 0, len(g.clients.clients))
+	for i, tc := range g.clients.clients {
+		diags = append(diags, diagnoseToken(r.Context(), i, tc.client))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(diags)
+}
+
+// diagnoseToken Check a token: its owner, its scopes and its remaining quota.
+/*
+ * @param ctx context.Context - The context
+ * @param index int - The position of the token
+ * @param client *github.Client - The client of the token
+ * @return TokenDiag - The diagnostic
+ */
+func diagnoseToken(ctx context.Context, index int, client *github.Client) TokenDiag {
+	diag := TokenDiag{Index: index, Scopes: []string{}}
+
+	user, resp, err := client.Users.Get(ctx, "")
+	if err != nil {
+		diag.Error = err.Error()
+		return diag
+	}
+	diag.Login = user.GetLogin()
+	for _, scope := range strings.Split(resp.Header.Get("X-OAuth-Scopes"), ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			diag.Scopes = append(diag.Scopes, scope)
+		}
+	}
+
+	limits, _, err := client.RateLimits(ctx)
+	if err != nil {
+		diag.Error = err.Error()
+		return diag
+	}
+	diag.Core = quotaInfo(limits.GetCore())
+	diag.Search = quotaInfo(limits.GetSearch())
+	return diag
+}
+
+// quotaInfo Convert a GitHub rate limit.
+/*
+ * @param rate *github.Rate - The rate limit
+ * @return *QuotaInfo - The quota, nil if unknown
+ */
+func quotaInfo(rate *github.Rate) *QuotaInfo {
+	if rate == nil {
+		return nil
+	}
+	return &QuotaInfo{
+		Limit:     rate.Limit,
+		Remaining: rate.Remaining,
+		Reset:     rate.Reset.Time,
+	}
+}
@@ -0,0 +1,128 @@
+package githubstats
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// adminRequest Build an admin request with the bearer token.
+/*
+ * @param method string - The method
+ * @param target string - The target
+ * @param token string - The bearer token (none if empty)
+ * @param body string - The body
+ * @return *http.Request - The request
+ */
+func adminRequest(method string, target string, token string, body string) *http.Request {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	return r
+}
+
+// TestAdminCache Check that the admin cache endpoint lists the live keys and requires the admin token.
+func TestAdminCache(t *testing.T) {
+	f := newFakeGitHub(t)
+	g := newTestGStats(t, f, Config{AdminToken: "secret"})
+	g.cache.Set("live", GitHubStats{}, time.Minute)
+	g.cache.Set("expired", GitHubStats{}, -time.Second)
+
+	if rec := serveRequest(g, adminRequest(http.MethodGet, "/admin/cache", "wrong", "")); rec.Code != http.StatusUnauthorized {
+		t.Errorf("status with a wrong token = %d, want 401", rec.Code)
+	}
+
+	rec := serveRequest(g, adminRequest(http.MethodGet, "/admin/cache", "secret", ""))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	var keys []CacheKeyInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &keys); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(keys) != 1 || keys[0].Key != "live" {
+		t.Errorf("keys = %+v, want only live", keys)
+	}
+}
+
+// TestAdminWarm Check that the warm endpoint fetches and caches the users, reporting the failures.
+func TestAdminWarm(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	g := newTestGStats(t, f, Config{AdminToken: "secret"})
+
+	rec := serveRequest(g, adminRequest(http.MethodPost, "/admin/warm", "secret", `{"usernames": ["octocat", "ghost"]}`))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	var resp WarmResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Warmed != 1 || resp.Failed["ghost"] == "" {
+		t.Errorf("response = %+v, want octocat warmed and ghost failed", resp)
+	}
+	if len(g.cache.Keys()) != 1 {
+		t.Errorf("cache keys = %v, want octocat only", g.cache.Keys())
+	}
+}
+
+// TestAdminDiag Check that the diag endpoint reports the owner, the scopes and the quota of each token.
+func TestAdminDiag(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handle("GET /user", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-OAuth-Scopes", "repo, read:org")
+		writeJSON(w, http.StatusOK, map[string]interface{}{"login": "octocat"})
+	})
+	reset := time.Now().Add(time.Hour).Unix()
+	f.handleJSON("GET /rate_limit", map[string]interface{}{
+		"resources": map[string]interface{}{
+			"core":   map[string]interface{}{"limit": 5000, "remaining": 4200, "reset": reset},
+			"search": map[string]interface{}{"limit": 30, "remaining": 29, "reset": reset},
+		},
+	})
+	g := newTestGStats(t, f, Config{AdminToken: "secret"})
+
+	rec := serveRequest(g, adminRequest(http.MethodGet, "/admin/diag", "secret", ""))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	var diags []TokenDiag
+	if err := json.Unmarshal(rec.Body.Bytes(), &diags); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(diags) != 1 {
+		t.Fatalf("diags = %+v, want one", diags)
+	}
+	diag := diags[0]
+	if diag.Error != "" || diag.Login != "octocat" || strings.Join(diag.Scopes, " ") != "repo read:org" {
+		t.Errorf("diag = %+v", diag)
+	}
+	if diag.Core == nil || diag.Core.Remaining != 4200 || diag.Search == nil || diag.Search.Limit != 30 {
+		t.Errorf("quota = %+v %+v", diag.Core, diag.Search)
+	}
+}
+
+// TestAdminSnapshot Check that a snapshot exported by one instance restores the entries into another.
+func TestAdminSnapshot(t *testing.T) {
+	f := newFakeGitHub(t)
+	source := newTestGStats(t, f, Config{AdminToken: "secret"})
+	source.cache.Set("octocat", GitHubStats{Username: "octocat"}, time.Minute)
+
+	rec := serveRequest(source, adminRequest(http.MethodGet, "/admin/snapshot", "secret", ""))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET status = %d", rec.Code)
+	}
+
+	target := newTestGStats(t, f, Config{AdminToken: "secret"})
+	if rec := serveRequest(target, adminRequest(http.MethodPost, "/admin/snapshot", "secret", rec.Body.String())); rec.Code != http.StatusNoContent {
+		t.Fatalf("POST status = %d", rec.Code)
+	}
+	if stats, found := target.cache.Get("octocat"); !found || stats.Username != "octocat" {
+		t.Errorf("restored entry = %+v, %v", stats, found)
+	}
+}
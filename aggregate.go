@@ -0,0 +1,256 @@
+package githubstats
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/github"
+)
+
+type MemberStats struct {
+	Username   string `json:"username"`
+	Followers  int    `json:"followers"`
+	TotalStars int    `json:"total_stars"`
+}
+
+type ContributorStat struct {
+	Login         string `json:"login"`
+	Contributions int    `json:"contributions"`
+}
+
+type OrgAggregate struct {
+	Org        string        `json:"org"`
+	Members    int           `json:"members"`
+	Followers  int           `json:"followers"`   // Sum of the members' followers
+	TotalStars int           `json:"total_stars"` // Sum of the members' stars
+	Breakdown  []MemberStats `json:"breakdown"`
+	Partial    bool          `json:"partial,omitempty"`  // Some members are left out of the sums
+	Warnings   []string      `json:"warnings,omitempty"` // Why each member was left out
+
+	// Only with include_contributors=true
+	TotalContributors  int               `json:"total_contributors,omitempty"`  // Unique contributors across the organization repositories
+	TotalContributions int               `json:"total_contributions,omitempty"` // Contributions across the organization repositories
+	TopContributors    []ContributorStat `json:"top_contributors,omitempty"`    // Contributors with the most contributions, up to MaxTopContributors
+}
+
+// orgHandler Handle the requests under OrgPath, currently only {org}/aggregate.
+/*
+ * @param w http.ResponseWriter - The response writer
+ * @param r *http.Request - The request
+ * @return void
+ */
+func (g *GStats) orgHandler(w http.ResponseWriter, r *http.Request) {
+	org, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, g.config.OrgPath), "/")
+	if org == "" || action != "aggregate" {
+		writeError(w, r, "Not found", http.StatusNotFound)
+		return
+	}
+	if !g.checkStrictParams(w, r, false, orgParams) {
+		return
+	}
+
+	// Check the request limit
+	limiter := g.limiterFor(g.config.OrgPath)
+	if !g.admit(r.Context(), limiter) {
+		g.writeRateLimited(w, r, limiter)
+		return
+	}
+
+	members, err := g.fetchOrgMembers(r.Context(), org)
+	if isNotFound(err) {
+		writeError(w, r, "Organization not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		g.writeStatsError(w, r, err)
+		return
+	}
+
+	// Each member costs a token like a stats request, the first one was taken by the admission
+	admitted := 1
+	for admitted < len(members) && limiter.Allow() {
+		admitted++
+	}
+
+	// Only the summed stats are fetched, whatever the query asks
+	results, errs := g.fetchAll(r.Context(), members[:min(admitted, len(members))], IncludeOptions{IncludeFollowers: true, IncludeStars: true})
+
+	aggregate := OrgAggregate{
+		Org:       org,
+		Members:   len(members),
+		Breakdown: make([]MemberStats, 0, len(results)),
+	}
+	// A failed member doesn't fail the others, the aggregate is only partial
+	for _, member := range members[len(results):] {
+		aggregate.Partial = true
+		aggregate.Warnings = append(aggregate.Warnings, member+": "+ErrRequestLimitExceeded.Error())
+	}
+	for i, stats := range results {
+		if errs[i] != nil {
+			aggregate.Partial = true
+			aggregate.Warnings = append(aggregate.Warnings, members[i]+": "+errs[i].Error())
+			continue
+		}
+		aggregate.Followers += stats.Followers
+		aggregate.TotalStars += stats.TotalStars
+		aggregate.Breakdown = append(aggregate.Breakdown, MemberStats{
+			Username:   stats.Username,
+			Followers:  stats.Followers,
+			TotalStars: stats.TotalStars,
+		})
+	}
+
+	if r.URL.Query().Get("include_contributors") == "true" {
+		contributions, err := g.fetchOrgContributions(r.Context(), org)
+		if errors.Is(err, ErrCallBudgetExhausted) {
+			aggregate.Partial = true
+			aggregate.Warnings = append(aggregate.Warnings, "contributors: cut short, the GitHub call budget is exhausted")
+		} else if err != nil {
+			g.writeStatsError(w, r, err)
+			return
+		}
+		aggregate.TotalContributors = len(contributions)
+		for _, n := range contributions {
+			aggregate.TotalContributions += n
+		}
+		aggregate.TopContributors = rankContributors(contributions, g.config.MaxTopContributors)
+	}
+
+	writeData(w, r, org+"-aggregate", g.envelope(aggregate))
+}
+
+// fetchOrgMembers List the logins of the organization members, up to MaxOrgMembers.
+/*
+ * Without being a member, the token only sees the public memberships.
+ *
+ * @param ctx context.Context - The context
+ * @param org string - The organization login
+ * @return []string, error - The logins, the error
+ */
+func (g *GStats) fetchOrgMembers(ctx context.Context, org string) ([]string, error) {
+	client := g.clientFor(ctx)
+	members := []string{}
+	err := g.guardedCall(ctx, func() error {
+		listOpts := &github.ListMembersOptions{ListOptions: github.ListOptions{PerPage: 100}}
+		for len(members) < g.config.MaxOrgMembers {
+			users, resp, err := client.Organizations.ListMembers(ctx, org, listOpts)
+			if err != nil {
+				return err
+			}
+
+			for _, user := range users {
+				if len(members) == g.config.MaxOrgMembers {
+					break
+				}
+				members = append(members, user.GetLogin())
+			}
+
+			if resp.NextPage == 0 {
+				break
+			}
+			listOpts.Page = resp.NextPage
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// fetchOrgContributions Sum the contributions of each contributor across the organization repositories.
+/*
+ * Up to MaxOrgRepos repositories are listed, and up to MaxContributorPages pages of contributors of each one are counted.
+ * The calls go through the circuit breaker and share a budget of MaxAPICallsPerRequest calls.
+ *
+ * @param ctx context.Context - The context
+ * @param org string - The organization login
+ * @return map[string]int, error - The contributions by login, the error (ErrCallBudgetExhausted with the contributions summed so far)
+ */
+func (g *GStats) fetchOrgContributions(ctx context.Context, org string) (map[string]int, error) {
+	if g.config.MaxAPICallsPerRequest > 0 {
+		ctx = withCallBudget(ctx, g.config.MaxAPICallsPerRequest)
+	}
+	client := g.clientFor(ctx)
+	contributions := make(map[string]int)
+	err := g.guardedCall(ctx, func() error {
+		listed := 0
+		listOpts := &github.RepositoryListByOrgOptions{ListOptions: github.ListOptions{PerPage: 100}}
+		for listed < g.config.MaxOrgRepos {
+			repos, resp, err := client.Repositories.ListByOrg(ctx, org, listOpts)
+			if err != nil {
+				return err
+			}
+
+			for _, repo := range repos {
+				if listed == g.config.MaxOrgRepos {
+					break
+				}
+				listed++
+				if err := g.countContributors(ctx, client, org, repo.GetName(), contributions); err != nil {
+					return err
+				}
+			}
+
+			if resp.NextPage == 0 {
+				break
+			}
+			listOpts.Page = resp.NextPage
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, ErrCallBudgetExhausted) {
+		return nil, err
+	}
+	return contributions, err
+}
+
+// countContributors Add the contributions of each contributor of a repository, up to MaxContributorPages pages.
+/*
+ * @param ctx context.Context - The context
+ * @param client *github.Client - The GitHub client
+ * @param owner string - The repository owner
+ * @param name string - The repository name
+ * @param contributions map[string]int - The contributions by login, updated
+ * @return error? - The error
+ */
+func (g *GStats) countContributors(ctx context.Context, client *github.Client, owner string, name string, contributions map[string]int) error {
+	listOpts := &github.ListContributorsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for page := 0; page < g.config.MaxContributorPages; page++ {
+		contributors, resp, err := client.Repositories.ListContributors(ctx, owner, name, listOpts)
+		if err != nil {
+			return err
+		}
+		for _, contributor := range contributors {
+			// Anonymous contributors have no login
+			if contributor.Login != nil {
+				contributions[*contributor.Login] += contributor.GetContributions()
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		listOpts.Page = resp.NextPage
+	}
+	return nil
+}
+
+// rankContributors Sort the contributors by contributions, most first.
+/*
+ * @param contributions map[string]int - The contributions by login
+ * @param limit int - The maximum number of contributors
+ * @return []ContributorStat - The ranking
+ */
+func rankContributors(contributions map[string]int, limit int) []ContributorStat {
+	ranking := make([]ContributorStat, 0, len(contributions))
+	for _, login := range sortedByContributions(contributions) {
+		if len(ranking) == limit {
+			break
+		}
+		ranking = append(ranking, ContributorStat{Login: login, Contributions: contributions[login]})
+	}
+	return ranking
+}
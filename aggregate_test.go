@@ -0,0 +1,217 @@
+package githubstats
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// handleOrgMembers Register an organization with two members, their followers and their repositories.
+/*
+ * @param f *fakeGitHub - The fake API
+ * @return void
+ */
+func handleOrgMembers(f *fakeGitHub) {
+	f.handleJSON("GET /orgs/acme/members", []map[string]interface{}{{"login": "alice"}, {"login": "bob"}})
+	f.handleUser("alice", map[string]interface{}{"followers": 3})
+	f.handleUser("bob", map[string]interface{}{"followers": 4})
+	f.handleJSON("GET /users/alice/repos", []map[string]interface{}{repoJSON("alice", "a", 10), repoJSON("alice", "b", 5)})
+	f.handleJSON("GET /users/bob/repos", []map[string]interface{}{repoJSON("bob", "c", 1)})
+}
+
+// TestOrgAggregate Check that the aggregate sums the followers and stars of the members.
+func TestOrgAggregate(t *testing.T) {
+	f := newFakeGitHub(t)
+	handleOrgMembers(f)
+	g := newTestGStats(t, f, Config{})
+
+	rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/org/acme/aggregate", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	var aggregate OrgAggregate
+	if err := json.Unmarshal(rec.Body.Bytes(), &aggregate); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if aggregate.Members != 2 || aggregate.Followers != 7 || aggregate.TotalStars != 16 {
+		t.Errorf("aggregate = %+v, want 2 members, 7 followers and 16 stars", aggregate)
+	}
+	if len(aggregate.Breakdown) != 2 {
+		t.Errorf("breakdown = %+v, want two members", aggregate.Breakdown)
+	}
+
+	if rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/org/ghost/aggregate", nil)); rec.Code != http.StatusNotFound {
+		t.Errorf("status of an unknown organization = %d, want 404", rec.Code)
+	}
+}
+
+// TestOrgAggregateCacheOptions Check that the stats cached by the aggregate aren't served to a request asking for other sections.
+func TestOrgAggregateCacheOptions(t *testing.T) {
+	f := newFakeGitHub(t)
+	handleOrgMembers(f)
+	g := newTestGStats(t, f, Config{})
+
+	if rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/org/acme/aggregate", nil)); rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=alice&include_repos=true", nil))
+	var stats GitHubStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(stats.Repositories) != 2 {
+		t.Errorf("Repositories = %+v, want the two repositories of alice", stats.Repositories)
+	}
+}
+
+// TestOrgAggregateContributors Check that a contributor of several repositories is counted once, with the sum of their contributions.
+func TestOrgAggregateContributors(t *testing.T) {
+	f := newFakeGitHub(t)
+	handleOrgMembers(f)
+	f.handleJSON("GET /orgs/acme/repos", []map[string]interface{}{repoJSON("acme", "api", 1), repoJSON("acme", "web", 1)})
+	f.handleJSON("GET /repos/acme/api/contributors", []map[string]interface{}{
+		{"login": "alice", "contributions": 10},
+		{"login": "bob", "contributions": 2},
+	})
+	f.handleJSON("GET /repos/acme/web/contributors", []map[string]interface{}{
+		{"login": "alice", "contributions": 5},
+		{"login": "carol", "contributions": 7},
+	})
+	g := newTestGStats(t, f, Config{})
+
+	rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/org/acme/aggregate?include_contributors=true", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	var aggregate OrgAggregate
+	if err := json.Unmarshal(rec.Body.Bytes(), &aggregate); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if aggregate.TotalContributors != 3 || aggregate.TotalContributions != 24 {
+		t.Errorf("TotalContributors = %d, TotalContributions = %d, want 3 and 24", aggregate.TotalContributors, aggregate.TotalContributions)
+	}
+	if len(aggregate.TopContributors) == 0 || aggregate.TopContributors[0] != (ContributorStat{Login: "alice", Contributions: 15}) {
+		t.Errorf("TopContributors = %+v, want alice first with 15", aggregate.TopContributors)
+	}
+}
+
+// TestOrgAggregatePartial Check that a failing member is reported instead of failing the aggregate of the others.
+func TestOrgAggregatePartial(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleJSON("GET /orgs/acme/members", []map[string]interface{}{{"login": "alice"}, {"login": "bob"}, {"login": "ghost"}})
+	f.handleUser("alice", map[string]interface{}{"followers": 3})
+	f.handleUser("bob", map[string]interface{}{"followers": 4})
+	f.handleJSON("GET /users/alice/repos", []map[string]interface{}{})
+	f.handleJSON("GET /users/bob/repos", []map[string]interface{}{})
+	g := newTestGStats(t, f, Config{})
+
+	rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/org/acme/aggregate", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	var aggregate OrgAggregate
+	if err := json.Unmarshal(rec.Body.Bytes(), &aggregate); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if aggregate.Members != 3 || aggregate.Followers != 7 || len(aggregate.Breakdown) != 2 {
+		t.Errorf("aggregate = %+v, want 3 members, alice and bob summed", aggregate)
+	}
+	if !aggregate.Partial || len(aggregate.Warnings) != 1 || !strings.HasPrefix(aggregate.Warnings[0], "ghost: ") {
+		t.Errorf("Partial = %v, Warnings = %v, want ghost reported", aggregate.Partial, aggregate.Warnings)
+	}
+}
+
+// TestOrgAggregateRateLimit Check that each member costs a limiter token, the members left without one being reported.
+func TestOrgAggregateRateLimit(t *testing.T) {
+	f := newFakeGitHub(t)
+	handleOrgMembers(f)
+	g := newTestGStats(t, f, Config{EndpointRateLimits: map[string]int{"/org/": 1}})
+
+	rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/org/acme/aggregate", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	var aggregate OrgAggregate
+	if err := json.Unmarshal(rec.Body.Bytes(), &aggregate); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(aggregate.Breakdown) != 1 || !aggregate.Partial || len(aggregate.Warnings) != 1 {
+		t.Errorf("aggregate = %+v, want alice only and bob reported", aggregate)
+	}
+	if calls := f.count("GET /users/bob"); calls != 0 {
+		t.Errorf("bob fetched %d times without a token", calls)
+	}
+}
+
+// TestOrgAggregateBreaker Check that the members aren't listed while the circuit breaker is open.
+func TestOrgAggregateBreaker(t *testing.T) {
+	f := newFakeGitHub(t)
+	handleOrgMembers(f)
+	g := newTestGStats(t, f, Config{BreakerThreshold: 1})
+	g.breaker.Failure()
+
+	if rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/org/acme/aggregate", nil)); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", rec.Code)
+	}
+	if calls := f.totalCalls(); calls != 0 {
+		t.Errorf("GitHub calls = %d, want 0", calls)
+	}
+}
+
+// TestOrgAggregateContributorPages Check that the contributors are paginated up to MaxContributorPages.
+func TestOrgAggregateContributorPages(t *testing.T) {
+	f := newFakeGitHub(t)
+	handleOrgMembers(f)
+	f.handleJSON("GET /orgs/acme/repos", []map[string]interface{}{repoJSON("acme", "api", 1)})
+	f.handle("GET /repos/acme/api/contributors", func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		page = max(page, 1)
+		if page < 3 {
+			setNextPage(w, r, page+1)
+		}
+		writeJSON(w, http.StatusOK, []map[string]interface{}{{"login": fmt.Sprintf("user-%d", page), "contributions": 1}})
+	})
+	g := newTestGStats(t, f, Config{MaxContributorPages: 2})
+
+	rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/org/acme/aggregate?include_contributors=true", nil))
+	var aggregate OrgAggregate
+	if err := json.Unmarshal(rec.Body.Bytes(), &aggregate); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if aggregate.TotalContributors != 2 {
+		t.Errorf("TotalContributors = %d, want the 2 first pages", aggregate.TotalContributors)
+	}
+	if calls := f.count("GET /repos/acme/api/contributors"); calls != 2 {
+		t.Errorf("contributor calls = %d, want 2", calls)
+	}
+}
+
+// TestOrgAggregateContributorsBudget Check that the contributors are cut short by MaxAPICallsPerRequest, the aggregate being partial.
+func TestOrgAggregateContributorsBudget(t *testing.T) {
+	f := newFakeGitHub(t)
+	handleOrgMembers(f)
+	f.handleJSON("GET /orgs/acme/repos", []map[string]interface{}{repoJSON("acme", "api", 1), repoJSON("acme", "web", 1)})
+	f.handleJSON("GET /repos/acme/api/contributors", []map[string]interface{}{{"login": "alice", "contributions": 10}})
+	f.handleJSON("GET /repos/acme/web/contributors", []map[string]interface{}{{"login": "carol", "contributions": 7}})
+	// The listing and the contributors of api
+	g := newTestGStats(t, f, Config{MaxAPICallsPerRequest: 2})
+
+	rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/org/acme/aggregate?include_contributors=true", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	var aggregate OrgAggregate
+	if err := json.Unmarshal(rec.Body.Bytes(), &aggregate); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if aggregate.TotalContributors != 1 || !aggregate.Partial {
+		t.Errorf("TotalContributors = %d, Partial = %v, want alice only and a partial aggregate", aggregate.TotalContributors, aggregate.Partial)
+	}
+	if calls := f.count("GET /repos/acme/web/contributors"); calls != 0 {
+		t.Errorf("web contributor calls = %d, want 0", calls)
+	}
+}
@@ -0,0 +1,55 @@
+package githubstats
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// decodeJSONBody Decode a JSON request body bounded by MaxRequestBodySize, writing the HTTP error on failure.
+/*
+ * A gzip Content-Encoding is decompressed first, the size limit applying to the decompressed body.
+ *
+ * @param w http.ResponseWriter - The response writer
+ * @param r *http.Request - The request
+ * @param v interface{} - The value the body is decoded into
+ * @return bool - Whether the body was decoded
+ */
+func (g *GStats) decodeJSONBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	var raw io.ReadCloser = r.Body
+	switch encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))); encoding {
+	case "", "identity":
+	case "gzip":
+		// The compressed body can't be larger than the limit either
+		gz, err := gzip.NewReader(http.MaxBytesReader(w, r.Body, g.config.MaxRequestBodySize))
+		if err != nil {
+			writeError(w, r, "Invalid gzip body", http.StatusBadRequest)
+			return false
+		}
+		raw = gz
+	default:
+		writeError(w, r, "Unsupported Content-Encoding: "+encoding, http.StatusUnsupportedMediaType)
+		return false
+	}
+
+	body := http.MaxBytesReader(w, raw, g.config.MaxRequestBodySize)
+	defer body.Close()
+
+	if err := json.NewDecoder(body).Decode(v); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeError(w, r, "Request body too large", http.StatusRequestEntityTooLarge)
+			return false
+		}
+		if errors.Is(err, gzip.ErrChecksum) || errors.Is(err, gzip.ErrHeader) {
+			writeError(w, r, "Invalid gzip body", http.StatusBadRequest)
+			return false
+		}
+		writeError(w, r, "Invalid JSON body", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,65 @@
+package githubstats
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestRequestBodyTooLarge Check that a body over MaxRequestBodySize is answered 413.
+func TestRequestBodyTooLarge(t *testing.T) {
+	f := newFakeGitHub(t)
+	g := newTestGStats(t, f, Config{AdminToken: "secret", MaxRequestBodySize: 64})
+
+	body := `{"usernames": ["` + strings.Repeat("a", 100) + `"]}`
+	if rec := serveRequest(g, adminRequest(http.MethodPost, "/admin/warm", "secret", body)); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want 413", rec.Code)
+	}
+	if calls := f.totalCalls(); calls != 0 {
+		t.Errorf("GitHub calls = %d, want 0", calls)
+	}
+}
+
+// gzipRequest Build an admin request with a gzip-encoded body.
+/*
+ * @param target string - The target
+ * @param body string - The uncompressed body
+ * @return *http.Request - The request
+ */
+func gzipRequest(target string, body string) *http.Request {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte(body))
+	gz.Close()
+	r := adminRequest(http.MethodPost, target, "secret", buf.String())
+	r.Header.Set("Content-Encoding", "gzip")
+	return r
+}
+
+// TestGzipRequestBody Check that a gzip body is decompressed and the size limit applies to the decompressed body.
+func TestGzipRequestBody(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	g := newTestGStats(t, f, Config{AdminToken: "secret", MaxRequestBodySize: 64})
+
+	if rec := serveRequest(g, gzipRequest("/admin/warm", `{"usernames": ["octocat"]}`)); rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	if calls := f.count("GET /users/octocat"); calls != 1 {
+		t.Errorf("user calls = %d, want 1", calls)
+	}
+
+	// Compresses below the limit but decompresses over it
+	body := `{"usernames": ["` + strings.Repeat("a", 1000) + `"]}`
+	if rec := serveRequest(g, gzipRequest("/admin/warm", body)); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status of a large decompressed body = %d, want 413", rec.Code)
+	}
+
+	r := adminRequest(http.MethodPost, "/admin/warm", "secret", `{"usernames": ["octocat"]}`)
+	r.Header.Set("Content-Encoding", "gzip")
+	if rec := serveRequest(g, r); rec.Code != http.StatusBadRequest {
+		t.Errorf("status of an invalid gzip body = %d, want 400", rec.Code)
+	}
+}
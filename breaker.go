@@ -0,0 +1,128 @@
+package githubstats
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// ErrCircuitOpen is returned when the circuit breaker rejects a GitHub call.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	failures  int
+	threshold int
+	cooldown  time.Duration
+	openedAt  time.Time
+	probing   bool
+}
+
+// NewCircuitBreaker Create a new circuit breaker.
+/*
+ * @param threshold int - The number of consecutive failures before opening
+ * @param cooldown time.Duration - The time to wait before a half-open probe
+ * @return *CircuitBreaker - The circuit breaker
+ */
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+	}
+}
+
+// Allow Allow a call. Once the cooldown has elapsed, a single probe is let through.
+/*
+ * @return bool - The result
+ */
+func (cb *CircuitBreaker) Allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.failures < cb.threshold {
+		return true
+	}
+
+	if cb.probing || time.Since(cb.openedAt) < cb.cooldown {
+		return false
+	}
+
+	// Half-open: let one probe through
+	cb.probing = true
+	return true
+}
+
+// Success Record a successful call and close the breaker.
+/*
+ * @return void
+ */
+func (cb *CircuitBreaker) Success() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.failures = 0
+	cb.probing = false
+}
+
+// Failure Record a failed call, opening the breaker once the threshold is reached.
+/*
+ * @return void
+ */
+func (cb *CircuitBreaker) Failure() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.failures++
+	cb.probing = false
+	if cb.failures >= cb.threshold {
+		cb.openedAt = time.Now()
+	}
+}
+
+// RetryAfter Get the time left before the next half-open probe.
+/*
+ * @return time.Duration - The duration
+ */
+func (cb *CircuitBreaker) RetryAfter() time.Duration {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	remaining := cb.cooldown - time.Since(cb.openedAt)
+	if remaining < time.Second {
+		return time.Second
+	}
+	return remaining
+}
+
+// isGitHubFailure Check if the error means GitHub is degraded (as opposed to a client error like a 404).
+/*
+ * @param ctx context.Context - The context of the request
+ * @param err error - The error
+ * @return bool - The result
+ */
+func isGitHubFailure(ctx context.Context, err error) bool {
+	// The caller went away or ran out of time, e.g. HandlerTimeout, GitHub is not to blame
+	if errors.Is(err, context.Canceled) || ctx.Err() != nil {
+		return false
+	}
+	// Also set from a successful lookup, without a GitHub error
+	if errors.Is(err, ErrUserSuspended) {
+		return false
+	}
+	// Stopped on our side before calling GitHub
+	if errors.Is(err, ErrCallBudgetExhausted) {
+		return false
+	}
+	// GitHub answered, the quota or the query is at fault
+	var rateLimitErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &rateLimitErr) || errors.As(err, &abuseErr) || errors.Is(err, errGraphQL) {
+		return false
+	}
+
+	var errResp *github.ErrorResponse
+	if errors.As(err, &errResp) && errResp.Response != nil && errResp.Response.StatusCode < 500 {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,108 @@
+package githubstats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// TestBreakerOpensAfterFailures Check that the breaker fails fast without calling GitHub once the threshold is reached.
+func TestBreakerOpensAfterFailures(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handle("GET /users/octocat", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"message": "Server Error"})
+	})
+	g := newTestGStats(t, f, Config{BreakerThreshold: 2, BreakerCooldown: time.Minute})
+
+	for i := 0; i < 2; i++ {
+		if _, err := g.GetGitHubStats("octocat", IncludeOptions{}); err == nil {
+			t.Fatalf("call %d: expected an error", i)
+		}
+	}
+	if calls := f.totalCalls(); calls != 2 {
+		t.Fatalf("GitHub calls = %d, want 2", calls)
+	}
+
+	_, err := g.GetGitHubStats("octocat", IncludeOptions{})
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("err = %v, want ErrCircuitOpen", err)
+	}
+	if calls := f.totalCalls(); calls != 2 {
+		t.Errorf("GitHub calls = %d after the breaker opened, want 2", calls)
+	}
+}
+
+// TestBreakerHalfOpenProbe Check that a single probe is let through after the cooldown and closes the breaker.
+func TestBreakerHalfOpenProbe(t *testing.T) {
+	cb := NewCircuitBreaker(1, 10*time.Millisecond)
+	cb.Failure()
+	if cb.Allow() {
+		t.Fatal("Allow = true right after opening")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if !cb.Allow() {
+		t.Fatal("Allow = false after the cooldown, want a probe")
+	}
+	if cb.Allow() {
+		t.Fatal("Allow = true while the probe is pending")
+	}
+
+	cb.Success()
+	if !cb.Allow() {
+		t.Error("Allow = false after a successful probe")
+	}
+}
+
+// TestIsGitHubFailure Check that only the errors blaming GitHub count as failures.
+func TestIsGitHubFailure(t *testing.T) {
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	for _, tc := range []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want bool
+	}{
+		{"server error", context.Background(), &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusBadGateway}}, true},
+		{"call timeout", context.Background(), fmt.Errorf("get user: %w", context.DeadlineExceeded), true},
+		{"not found", context.Background(), &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound}}, false},
+		{"rate limit", context.Background(), &github.RateLimitError{Response: &http.Response{StatusCode: http.StatusForbidden}}, false},
+		{"abuse rate limit", context.Background(), &github.AbuseRateLimitError{Response: &http.Response{StatusCode: http.StatusForbidden}}, false},
+		{"graphql", context.Background(), fmt.Errorf("%w: Could not resolve to a User", errGraphQL), false},
+		{"call budget", context.Background(), fmt.Errorf("list repos: %w", ErrCallBudgetExhausted), false},
+		{"handler timeout", expired, fmt.Errorf("get user: %w", context.DeadlineExceeded), false},
+	} {
+		if got := isGitHubFailure(tc.ctx, tc.err); got != tc.want {
+			t.Errorf("%s: isGitHubFailure = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+// TestBreakerIgnoresRateLimits Check that rate limited requests don't open the breaker and stay ErrRateLimited.
+func TestBreakerIgnoresRateLimits(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handle("GET /users/octocat", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", fmt.Sprint(time.Now().Add(time.Hour).Unix()))
+		writeJSON(w, http.StatusForbidden, map[string]string{"message": "API rate limit exceeded for 127.0.0.1."})
+	})
+	g := newTestGStats(t, f, Config{BreakerThreshold: 2, BreakerCooldown: time.Minute})
+
+	for i := 0; i < 3; i++ {
+		_, err := g.GetGitHubStats("octocat", IncludeOptions{})
+		if !errors.Is(err, ErrRateLimited) || errors.Is(err, ErrGitHubUnavailable) {
+			t.Errorf("call %d: err = %v, want ErrRateLimited", i, err)
+		}
+	}
+	if !g.breaker.Allow() {
+		t.Error("the breaker opened on rate limited requests")
+	}
+}
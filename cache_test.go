@@ -0,0 +1,134 @@
+package githubstats
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// sampleStats Build stats with a few repositories, repetitive enough to compress well.
+/*
+ * @param repos int - The number of repositories
+ * @return GitHubStats - The stats
+ */
+func sampleStats(repos int) GitHubStats {
+	stats := GitHubStats{Username: "octocat", Followers: 12, TotalStars: 34, Organizations: []string{"github"}}
+	for i := 0; i < repos; i++ {
+		stats.Repositories = append(stats.Repositories, RepoStats{
+			Name:         fmt.Sprintf("repository-%d", i),
+			Stars:        i,
+			Contributors: map[string]int{"octocat": 1000 * i, "hubot": 10 * i},
+		})
+	}
+	return stats
+}
+
+// largeStats Build stats well over a 512 bytes entry, gzipped or not.
+/*
+ * @return GitHubStats - The stats
+ */
+func largeStats() GitHubStats {
+	stats := GitHubStats{Username: "octocat"}
+	for i := 0; i < 100; i++ {
+		stats.Repositories = append(stats.Repositories, RepoStats{
+			Name:      fmt.Sprintf("repository-%d", i*7919),
+			Stars:     i * 31,
+			Languages: map[string]int{"Go": 1000*i + 17, "Shell": 10*i + 3},
+		})
+	}
+	return stats
+}
+
+// TestCompressedCacheRoundTrip Check that a compressed entry reads back unchanged and takes less room.
+func TestCompressedCacheRoundTrip(t *testing.T) {
+	stats := sampleStats(50)
+	plain, compressed := NewCache(), NewCompressedCache()
+	plain.Set("key", stats, time.Minute)
+	compressed.Set("key", stats, time.Minute)
+
+	got, found := compressed.Get("key")
+	if !found {
+		t.Fatal("compressed entry not found")
+	}
+	if !reflect.DeepEqual(got, stats) {
+		t.Errorf("Get = %+v, want %+v", got, stats)
+	}
+
+	plainData, _ := json.Marshal(plain.store["key"].Stats)
+	plainSize, compressedSize := len(plainData), len(compressed.store["key"].compressed)
+	if compressedSize == 0 || compressedSize >= plainSize {
+		t.Errorf("compressed size = %d, want less than %d", compressedSize, plainSize)
+	}
+}
+
+// TestCacheKeysSkipExpired Check that Keys and Entries leave out the expired entries.
+func TestCacheKeysSkipExpired(t *testing.T) {
+	c := NewCache()
+	c.Set("b", GitHubStats{Username: "b"}, time.Minute)
+	c.Set("a", GitHubStats{Username: "a"}, time.Minute)
+	c.Set("expired", GitHubStats{Username: "expired"}, -time.Second)
+
+	if keys := c.Keys(); !reflect.DeepEqual(keys, []string{"a", "b"}) {
+		t.Errorf("Keys = %v, want [a b]", keys)
+	}
+	if _, found := c.Entries()["expired"]; found {
+		t.Error("Entries lists the expired entry")
+	}
+}
+
+// TestCacheSnapshotRestore Check that a restored snapshot holds the same stats, their time left counted from the restore.
+func TestCacheSnapshotRestore(t *testing.T) {
+	source := NewCache()
+	source.Set("live", sampleStats(2), time.Hour)
+	source.Set("expired", sampleStats(1), -time.Second)
+	snapshot := source.Snapshot()
+
+	target := NewCache()
+	target.Restore(snapshot)
+	got, found := target.Get("live")
+	if !found {
+		t.Fatal("restored entry not found")
+	}
+	if !reflect.DeepEqual(got, sampleStats(2)) {
+		t.Errorf("Get = %+v, want %+v", got, sampleStats(2))
+	}
+	if _, found := target.Get("expired"); found {
+		t.Error("expired entry found after the restore")
+	}
+	if left := time.Until(target.Snapshot()["live"].Expiration); left < 59*time.Minute || left > time.Hour {
+		t.Errorf("time left = %v, want about an hour", left)
+	}
+}
+
+// TestMaxCacheEntrySize Check that a value over MaxEntrySize isn't cached while a small one is.
+func TestMaxCacheEntrySize(t *testing.T) {
+	for name, c := range map[string]*Cache{"plain": NewCache(), "compressed": NewCompressedCache()} {
+		c.SetMaxEntrySize(512)
+		c.Set("small", GitHubStats{Username: "octocat"}, time.Minute)
+		c.Set("large", largeStats(), time.Minute)
+
+		if _, found := c.Get("small"); !found {
+			t.Errorf("%s: small entry not cached", name)
+		}
+		if _, found := c.Get("large"); found {
+			t.Errorf("%s: oversized entry cached", name)
+		}
+	}
+}
+
+// TestMaxCacheEntrySizeSharedCache Check that a MaxCacheEntrySize differing from the one of a shared Cache is rejected.
+func TestMaxCacheEntrySizeSharedCache(t *testing.T) {
+	shared := NewCache()
+	shared.SetMaxEntrySize(512)
+
+	if _, err := NewGStats(Config{Token: "test-token", Cache: shared, MaxCacheEntrySize: 1024}); err == nil {
+		t.Error("NewGStats accepted a MaxCacheEntrySize the shared Cache ignores")
+	}
+	for _, size := range []int{0, 512} {
+		if _, err := NewGStats(Config{Token: "test-token", Cache: shared, MaxCacheEntrySize: size}); err != nil {
+			t.Errorf("NewGStats with MaxCacheEntrySize %d: %v", size, err)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package githubstats
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// ErrCallBudgetExhausted is returned by the GitHub calls made once MaxAPICallsPerRequest is reached.
+var ErrCallBudgetExhausted = errors.New("github call budget exhausted")
+
+type callBudgetKey struct{}
+
+// callBudget A number of GitHub calls shared by every call of a stats fetch, retries included.
+type callBudget struct {
+	mu        sync.Mutex
+	remaining int
+}
+
+// callBudgetTransport Fail the GitHub calls once the call budget of the request is exhausted, without sending them.
+type callBudgetTransport struct {
+	base http.RoundTripper
+}
+
+// withCallBudget Attach a call budget to the context.
+/*
+ * @param ctx context.Context - The context
+ * @param calls int - The maximum number of calls
+ * @return context.Context - The context
+ */
+func withCallBudget(ctx context.Context, calls int) context.Context {
+	return context.WithValue(ctx, callBudgetKey{}, &callBudget{remaining: calls})
+}
+
+// callBudgetFrom Get the call budget of the context.
+/*
+ * @param ctx context.Context - The context
+ * @return *callBudget - The budget, nil if unlimited
+ */
+func callBudgetFrom(ctx context.Context) *callBudget {
+	budget, _ := ctx.Value(callBudgetKey{}).(*callBudget)
+	return budget
+}
+
+// take Take one call from the budget.
+/*
+ * @return bool - Whether a call was left
+ */
+func (b *callBudget) take() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.remaining <= 0 {
+		return false
+	}
+	b.remaining--
+	return true
+}
+
+// exhausted Check if no call is left, always false for a nil budget.
+/*
+ * @return bool - The result
+ */
+func (b *callBudget) exhausted() bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.remaining <= 0
+}
+
+// RoundTrip Execute the request if the budget allows.
+/*
+ * @param req *http.Request - The request
+ * @return *http.Response, error - The response, the error
+ */
+func (t *callBudgetTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if budget := callBudgetFrom(req.Context()); budget != nil && !budget.take() {
+		return nil, ErrCallBudgetExhausted
+	}
+	return t.base.RoundTrip(req)
+}
+
+// budgetCut End a stats fetch cut short by the call budget, keeping the sections fetched so far.
+/*
+ * @param stats *GitHubStats - The stats fetched so far
+ * @param err error - The error of the section
+ * @return GitHubStats, error - The partial stats or the error
+ */
+func budgetCut(stats *GitHubStats, err error) (GitHubStats, error) {
+	if !errors.Is(err, ErrCallBudgetExhausted) {
+		return GitHubStats{}, err
+	}
+	stats.Partial = true
+	stats.BudgetLimited = true
+	stats.Warnings = append(stats.Warnings, "the GitHub call budget is exhausted, the remaining sections are skipped")
+	return *stats, nil
+}
@@ -0,0 +1,78 @@
+package githubstats
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestCallBudget Check that the calls stop at MaxAPICallsPerRequest, the response being marked partial.
+func TestCallBudget(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	f.handleJSON("GET /users/octocat/repos", []map[string]interface{}{
+		repoJSON("octocat", "a", 1), repoJSON("octocat", "b", 1), repoJSON("octocat", "c", 1), repoJSON("octocat", "d", 1),
+	})
+	f.handleJSON("GET /repos/octocat/{repo}/contributors", []map[string]interface{}{{"login": "alice", "contributions": 1}})
+	g := newTestGStats(t, f, Config{MaxAPICallsPerRequest: 3})
+
+	stats, err := g.GetGitHubStats("octocat", IncludeOptions{IncludeRepos: true, IncludeContributors: true, IncludeFirstNRepos: AllRepos})
+	if err != nil {
+		t.Fatalf("GetGitHubStats: %v", err)
+	}
+	if !stats.Partial || !stats.BudgetLimited {
+		t.Errorf("Partial = %v, BudgetLimited = %v, want both", stats.Partial, stats.BudgetLimited)
+	}
+	if calls := f.totalCalls(); calls != 3 {
+		t.Errorf("GitHub calls = %d, want 3", calls)
+	}
+}
+
+// TestCallBudgetNoRetry Check that a retry rejected by the call budget isn't retried again.
+func TestCallBudgetNoRetry(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	f.handle("GET /users/octocat/repos", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"message": "Unavailable"})
+	})
+	g := newTestGStats(t, f, Config{MaxAPICallsPerRequest: 2, MaxRetriesPerRequest: 3})
+
+	start := time.Now()
+	g.GetGitHubStats("octocat", IncludeOptions{IncludeRepos: true})
+	// A single backoff of 100ms, the next ones would add 600ms
+	if elapsed := time.Since(start); elapsed > 400*time.Millisecond {
+		t.Errorf("took %v, want the retries to stop with the budget", elapsed)
+	}
+	if calls := f.count("GET /users/octocat/repos"); calls != 1 {
+		t.Errorf("repository listing calls = %d, want 1", calls)
+	}
+}
+
+// TestCallBudgetCached Check that the stats cut short by the call budget are cached for PartialCacheDuration.
+func TestCallBudgetCached(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	f.handleJSON("GET /users/octocat/repos", []map[string]interface{}{
+		repoJSON("octocat", "a", 1), repoJSON("octocat", "b", 1), repoJSON("octocat", "c", 1), repoJSON("octocat", "d", 1),
+	})
+	f.handleJSON("GET /repos/octocat/{repo}/contributors", []map[string]interface{}{{"login": "alice", "contributions": 1}})
+	g := newTestGStats(t, f, Config{MaxAPICallsPerRequest: 3, PartialCacheDuration: 200 * time.Millisecond})
+	url := "/stats?username=octocat&include_repos=true&include_contributors=true&include_first_n_repos=-1"
+
+	if rec := serveRequest(g, httptest.NewRequest(http.MethodGet, url, nil)); rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if rec := serveRequest(g, httptest.NewRequest(http.MethodGet, url, nil)); rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if calls := f.totalCalls(); calls != 3 {
+		t.Errorf("GitHub calls = %d, want 3, the second request served from the cache", calls)
+	}
+
+	time.Sleep(250 * time.Millisecond)
+	serveRequest(g, httptest.NewRequest(http.MethodGet, url, nil))
+	if calls := f.totalCalls(); calls != 6 {
+		t.Errorf("GitHub calls = %d, want 6 once the partial entry expired", calls)
+	}
+}
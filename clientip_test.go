@@ -0,0 +1,35 @@
+package githubstats
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestClientIP Check that the forwarded headers are only honored from a trusted proxy.
+func TestClientIP(t *testing.T) {
+	f := newFakeGitHub(t)
+	g := newTestGStats(t, f, Config{TrustedProxies: []string{"10.0.0.0/8"}})
+
+	for _, tc := range []struct {
+		name, remote, forwarded, realIP, want string
+	}{
+		{"spoofed", "203.0.113.7:1234", "198.51.100.1", "", "203.0.113.7"},
+		{"trusted proxy", "10.0.0.2:1234", "198.51.100.1", "", "198.51.100.1"},
+		{"proxy chain", "10.0.0.2:1234", "192.0.2.9, 198.51.100.1, 10.0.0.5", "", "198.51.100.1"},
+		{"real ip", "10.0.0.2:1234", "", "198.51.100.2", "198.51.100.2"},
+		{"no header", "10.0.0.2:1234", "", "", "10.0.0.2"},
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/stats", nil)
+		r.RemoteAddr = tc.remote
+		if tc.forwarded != "" {
+			r.Header.Set("X-Forwarded-For", tc.forwarded)
+		}
+		if tc.realIP != "" {
+			r.Header.Set("X-Real-IP", tc.realIP)
+		}
+		if got := g.clientIP(r); got != tc.want {
+			t.Errorf("%s: clientIP = %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
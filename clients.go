@@ -0,0 +1,189 @@
+package githubstats
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/github"
+	"golang.org/x/oauth2"
+)
+
+// DefaultAPIVersion GitHub REST API version sent when Config.APIVersion is empty.
+const DefaultAPIVersion = "2022-11-28"
+
+type tokenQuota struct {
+	mu        sync.Mutex
+	known     bool
+	remaining int
+	reset     time.Time
+}
+
+type tokenClient struct {
+	client *github.Client
+	quota  *tokenQuota
+}
+
+type clientPool struct {
+	mu      sync.Mutex
+	clients []*tokenClient
+	next    int
+}
+
+// quotaTransport Track the remaining core quota of a token from the GitHub response headers.
+type quotaTransport struct {
+	base  http.RoundTripper
+	quota *tokenQuota
+}
+
+// RoundTrip Execute the request and record the rate limit headers.
+/*
+ * @param req *http.Request - The request
+ * @return *http.Response, error - The response, the error
+ */
+func (t *quotaTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	// The search API has its own quota, don't mix it with the core one
+	if err == nil && !strings.HasPrefix(req.URL.Path, "/search/") {
+		t.quota.update(resp.Header)
+	}
+	return resp, err
+}
+
+// apiVersionTransport Pin the GitHub REST API version of every request.
+type apiVersionTransport struct {
+	base    http.RoundTripper
+	version string
+}
+
+// RoundTrip Execute the request with the X-GitHub-Api-Version header.
+/*
+ * @param req *http.Request - The request
+ * @return *http.Response, error - The response, the error
+ */
+func (t *apiVersionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTrippers must not modify the caller's request
+	req = req.Clone(req.Context())
+	req.Header.Set("X-GitHub-Api-Version", t.version)
+	return t.base.RoundTrip(req)
+}
+
+// update Update the quota from the rate limit headers.
+/*
+ * @param header http.Header - The response headers
+ * @return void
+ */
+func (q *tokenQuota) update(header http.Header) {
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.known = true
+	q.remaining = remaining
+	q.reset = time.Unix(reset, 0)
+}
+
+// available Check if the token still has quota left.
+/*
+ * @return bool - The result
+ */
+func (q *tokenQuota) available() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return !q.known || q.remaining > 0 || time.Now().After(q.reset)
+}
+
+// newGitHubClient Create a GitHub client authenticated with the given token source.
+/*
+ * @param ts oauth2.TokenSource - The token source
+ * @param config Config - The configuration
+ * @param base http.RoundTripper - The transport shared by every client, see baseTransport
+ * @return *tokenClient - The client and its quota
+ */
+func newGitHubClient(ts oauth2.TokenSource, config Config, base http.RoundTripper) *tokenClient {
+	quota := &tokenQuota{}
+	var transport http.RoundTripper = &apiVersionTransport{base: base, version: config.APIVersion}
+	transport = &quotaTransport{base: transport, quota: quota}
+	if config.ConditionalRequests {
+		transport = &conditionalTransport{base: transport, entries: make(map[string]conditionalEntry)}
+	}
+	// Retries count against the budget, they consume the quota too
+	transport = &callBudgetTransport{base: transport}
+	httpClient := &http.Client{
+		Transport: &oauth2.Transport{
+			Source: ts,
+			Base:   &retryTransport{base: transport},
+		},
+	}
+	return &tokenClient{
+		client: github.NewClient(httpClient),
+		quota:  quota,
+	}
+}
+
+// baseTransport Get the HTTP transport of the GitHub calls, capping the connections per host when configured.
+/*
+ * Built once by setup, the caps only hold if every client shares it.
+ *
+ * @param config Config - The configuration
+ * @return http.RoundTripper - The transport
+ */
+func baseTransport(config Config) http.RoundTripper {
+	if config.MaxIdleConnsPerHost == 0 && config.MaxConnsPerHost == 0 {
+		return http.DefaultTransport
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if config.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = config.MaxIdleConnsPerHost
+	}
+	if config.MaxConnsPerHost > 0 {
+		transport.MaxConnsPerHost = config.MaxConnsPerHost
+	}
+	return transport
+}
+
+// newClientPool Create a pool of GitHub clients, one per token source.
+/*
+ * @param sources []oauth2.TokenSource - The token sources
+ * @param config Config - The configuration
+ * @param base http.RoundTripper - The transport shared by every client
+ * @return *clientPool - The pool
+ */
+func newClientPool(sources []oauth2.TokenSource, config Config, base http.RoundTripper) *clientPool {
+	pool := &clientPool{}
+	for _, ts := range sources {
+		pool.clients = append(pool.clients, newGitHubClient(ts, config, base))
+	}
+	return pool
+}
+
+// pick Pick the next client in round-robin order, skipping tokens without quota left.
+/*
+ * @return *github.Client - The client
+ */
+func (p *clientPool) pick() *github.Client {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i := 0; i < len(p.clients); i++ {
+		tc := p.clients[(p.next+i)%len(p.clients)]
+		if tc.quota.available() {
+			p.next = (p.next + i + 1) % len(p.clients)
+			return tc.client
+		}
+	}
+
+	// Every token is exhausted, let GitHub report the rate limit error
+	tc := p.clients[p.next]
+	p.next = (p.next + 1) % len(p.clients)
+	return tc.client
+}
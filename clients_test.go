@@ -0,0 +1,210 @@
+package githubstats
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// recordTokens Register the user route, counting the calls made with each token.
+/*
+ * @param f *fakeGitHub - The fake API
+ * @param login string - The user
+ * @param header func(w http.ResponseWriter, token string) - Sets the response headers of a token (nil for none)
+ * @return func() map[string]int - The calls by token
+ */
+func recordTokens(f *fakeGitHub, login string, header func(w http.ResponseWriter, token string)) func() map[string]int {
+	var mu sync.Mutex
+	tokens := make(map[string]int)
+	f.handle("GET /users/"+login, func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get("Authorization")
+		mu.Lock()
+		tokens[token]++
+		mu.Unlock()
+		if header != nil {
+			header(w, token)
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"login": login})
+	})
+	return func() map[string]int {
+		mu.Lock()
+		defer mu.Unlock()
+		copied := make(map[string]int, len(tokens))
+		for token, n := range tokens {
+			copied[token] = n
+		}
+		return copied
+	}
+}
+
+// TestTokenRotation Check that the calls are spread over every configured token.
+func TestTokenRotation(t *testing.T) {
+	f := newFakeGitHub(t)
+	calls := recordTokens(f, "octocat", nil)
+	g := newTestGStats(t, f, Config{Token: "token-a", Tokens: []string{"token-b"}})
+
+	for i := 0; i < 4; i++ {
+		if _, err := g.GetGitHubStats("octocat", IncludeOptions{}); err != nil {
+			t.Fatalf("GetGitHubStats: %v", err)
+		}
+	}
+	want := map[string]int{"Bearer token-a": 2, "Bearer token-b": 2}
+	if got := calls(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("calls by token = %v, want %v", got, want)
+	}
+}
+
+// TestTokenRotationSkipsExhausted Check that a token without quota left is skipped until its reset.
+func TestTokenRotationSkipsExhausted(t *testing.T) {
+	f := newFakeGitHub(t)
+	reset := fmt.Sprint(time.Now().Add(time.Hour).Unix())
+	calls := recordTokens(f, "octocat", func(w http.ResponseWriter, token string) {
+		remaining := "100"
+		if token == "Bearer token-a" {
+			remaining = "0"
+		}
+		w.Header().Set("X-RateLimit-Remaining", remaining)
+		w.Header().Set("X-RateLimit-Reset", reset)
+	})
+	g := newTestGStats(t, f, Config{Token: "token-a", Tokens: []string{"token-b"}})
+
+	for i := 0; i < 4; i++ {
+		if _, err := g.GetGitHubStats("octocat", IncludeOptions{}); err != nil {
+			t.Fatalf("GetGitHubStats: %v", err)
+		}
+	}
+	// token-a is only used once, before its quota is known
+	want := map[string]int{"Bearer token-a": 1, "Bearer token-b": 3}
+	if got := calls(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("calls by token = %v, want %v", got, want)
+	}
+}
+
+// refreshingSource A token source handing out a new token, already close to its expiry, on every call.
+type refreshingSource struct {
+	mu    sync.Mutex
+	calls int
+}
+
+// Token Get the next token.
+/*
+ * @return *oauth2.Token, error - The token, the error
+ */
+func (s *refreshingSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	// Within the expiry delta of oauth2, so the next request refreshes it
+	return &oauth2.Token{AccessToken: fmt.Sprint("token-", s.calls), Expiry: time.Now().Add(time.Second)}, nil
+}
+
+// TestTokenSourceRefresh Check that an expiring token of the TokenSource is refreshed before the next call.
+func TestTokenSourceRefresh(t *testing.T) {
+	f := newFakeGitHub(t)
+	calls := recordTokens(f, "octocat", nil)
+	g := newTestGStats(t, f, Config{TokenSource: &refreshingSource{}})
+
+	for i := 0; i < 2; i++ {
+		if _, err := g.GetGitHubStats("octocat", IncludeOptions{}); err != nil {
+			t.Fatalf("GetGitHubStats: %v", err)
+		}
+	}
+	want := map[string]int{"Bearer token-1": 1, "Bearer token-2": 1}
+	if got := calls(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("calls by token = %v, want %v", got, want)
+	}
+}
+
+// TestBaseTransport Check that the connection caps are applied to a copy of the default transport.
+func TestBaseTransport(t *testing.T) {
+	if got := baseTransport(Config{}); got != http.DefaultTransport {
+		t.Errorf("baseTransport without caps = %T, want http.DefaultTransport", got)
+	}
+
+	transport, ok := baseTransport(Config{MaxIdleConnsPerHost: 7, MaxConnsPerHost: 3}).(*http.Transport)
+	if !ok {
+		t.Fatal("baseTransport with caps is not an *http.Transport")
+	}
+	if transport.MaxIdleConnsPerHost != 7 || transport.MaxConnsPerHost != 3 {
+		t.Errorf("MaxIdleConnsPerHost = %d, MaxConnsPerHost = %d, want 7 and 3", transport.MaxIdleConnsPerHost, transport.MaxConnsPerHost)
+	}
+	if transport == http.DefaultTransport || http.DefaultTransport.(*http.Transport).MaxConnsPerHost != 0 {
+		t.Error("http.DefaultTransport was modified")
+	}
+}
+
+// countingTransport A transport counting the requests it sends.
+type countingTransport struct {
+	calls atomic.Int32
+}
+
+// RoundTrip Count and send the request.
+/*
+ * @param req *http.Request - The request
+ * @return *http.Response, error - The response, the error
+ */
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.calls.Add(1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// TestSharedTransport Check that the clients of the callers' tokens go through the transport built by setup, like the pooled ones.
+func TestSharedTransport(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	g := newTestGStats(t, f, Config{AllowUserTokens: true, MaxConnsPerHost: 3})
+	if _, ok := g.transport.(*http.Transport); !ok {
+		t.Fatalf("transport = %T, want the capped *http.Transport", g.transport)
+	}
+
+	shared := &countingTransport{}
+	g.transport = shared
+	r := httptest.NewRequest(http.MethodGet, "/stats?username=octocat", nil)
+	r.Header.Set("Authorization", "Bearer user-token")
+	client := userClient(g.withUserClient(r).Context())
+	f.point(client)
+	if _, _, err := client.Users.Get(context.Background(), "octocat"); err != nil {
+		t.Fatalf("Users.Get: %v", err)
+	}
+
+	pool := newClientPool([]oauth2.TokenSource{oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "test-token"})}, g.config, shared)
+	f.point(pool.pick())
+	if _, _, err := pool.pick().Users.Get(context.Background(), "octocat"); err != nil {
+		t.Fatalf("Users.Get: %v", err)
+	}
+	if calls := shared.calls.Load(); calls != 2 {
+		t.Errorf("calls through the shared transport = %d, want 2", calls)
+	}
+}
+
+// TestAPIVersionHeader Check that every GitHub call sends the configured X-GitHub-Api-Version, DefaultAPIVersion if empty.
+func TestAPIVersionHeader(t *testing.T) {
+	for configured, want := range map[string]string{"": DefaultAPIVersion, "2026-03-10": "2026-03-10"} {
+		f := newFakeGitHub(t)
+		var mu sync.Mutex
+		versions := make(map[string]int)
+		f.handle("GET /users/octocat", func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			versions[r.Header.Get("X-GitHub-Api-Version")]++
+			mu.Unlock()
+			writeJSON(w, http.StatusOK, map[string]interface{}{"login": "octocat"})
+		})
+		g := newTestGStats(t, f, Config{APIVersion: configured})
+
+		if _, err := g.GetGitHubStats("octocat", IncludeOptions{}); err != nil {
+			t.Fatalf("GetGitHubStats: %v", err)
+		}
+		mu.Lock()
+		if versions[want] != 1 || len(versions) != 1 {
+			t.Errorf("APIVersion %q: versions sent = %v, want %s", configured, versions, want)
+		}
+		mu.Unlock()
+	}
+}
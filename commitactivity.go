@@ -0,0 +1,85 @@
+package githubstats
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// computingRepo A returned repository whose commit activity GitHub is still computing.
+type computingRepo struct {
+	index int    // Position in the returned repositories
+	owner string // Owner login of the repository
+}
+
+// fetchWeeklyCommits Fetch the number of commits of each of the last 52 weeks of a repository, oldest first.
+/*
+ * @param ctx context.Context - The context
+ * @param client *github.Client - The GitHub client
+ * @param owner string - The repository owner
+ * @param repo string - The repository name
+ * @return []int, error - The weekly commits (nil if GitHub is still computing them), the error
+ */
+func fetchWeeklyCommits(ctx context.Context, client *github.Client, owner string, repo string) ([]int, error) {
+	weeks, resp, err := client.Repositories.ListCommitActivity(ctx, owner, repo)
+	// The {} body GitHub sends with the 202 replaces the AcceptedError with a decoding error
+	var acceptedErr *github.AcceptedError
+	if errors.As(err, &acceptedErr) || (resp != nil && resp.StatusCode == http.StatusAccepted) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	commits := make([]int, 0, len(weeks))
+	for _, week := range weeks {
+		commits = append(commits, week.GetTotal())
+	}
+	return commits, nil
+}
+
+// retryWeeklyCommits Retry the repositories whose commit activity GitHub is still computing.
+/*
+ * All of them are retried after each CommitActivityRetryDelay, so the waits don't add up with the number of repositories.
+ * No new round starts past the deadline.
+ *
+ * @param ctx context.Context - The context
+ * @param client *github.Client - The GitHub client
+ * @param repos []RepoStats - The returned repositories, filled in place
+ * @param computing []computingRepo - The repositories still computing
+ * @param deadline time.Time - The time after which no round starts (none if zero)
+ * @return []computingRepo, error - The repositories still computing, the error
+ */
+func (g *GStats) retryWeeklyCommits(ctx context.Context, client *github.Client, repos []RepoStats, computing []computingRepo, deadline time.Time) ([]computingRepo, error) {
+	delay := g.config.CommitActivityRetryDelay
+	for attempt := 0; attempt < g.config.CommitActivityRetries && len(computing) > 0; attempt++ {
+		if !deadline.IsZero() && time.Now().Add(delay).After(deadline) {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return computing, ctx.Err()
+		case <-time.After(delay):
+		}
+
+		var still []computingRepo
+		for _, repo := range computing {
+			callCtx, cancel := withCallTimeout(ctx, g.config.GitHubTimeout)
+			commits, err := fetchWeeklyCommits(callCtx, client, repo.owner, repos[repo.index].Name)
+			cancel()
+			if err != nil {
+				return computing, err
+			}
+			if commits == nil {
+				still = append(still, repo)
+				continue
+			}
+			repos[repo.index].WeeklyCommits = commits
+		}
+		computing = still
+	}
+	return computing, nil
+}
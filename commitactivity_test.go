@@ -0,0 +1,75 @@
+package githubstats
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestWeeklyCommits Check that the weekly commits are retried while GitHub answers 202, then filled oldest first.
+func TestWeeklyCommits(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	f.handleJSON("GET /users/octocat/repos", []map[string]interface{}{repoJSON("octocat", "hello", 1)})
+	var calls atomic.Int32
+	f.handle("GET /repos/octocat/hello/stats/commit_activity", func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			writeJSON(w, http.StatusAccepted, map[string]interface{}{})
+			return
+		}
+		writeJSON(w, http.StatusOK, []map[string]interface{}{
+			{"total": 3, "week": 1727568000},
+			{"total": 0, "week": 1728172800},
+			{"total": 7, "week": 1728777600},
+		})
+	})
+	g := newTestGStats(t, f, Config{CommitActivityRetryDelay: 10 * time.Millisecond})
+
+	stats, err := g.GetGitHubStats("octocat", IncludeOptions{IncludeRepos: true, IncludeCommitActivity: true, IncludeFirstNRepos: AllRepos})
+	if err != nil {
+		t.Fatalf("GetGitHubStats: %v", err)
+	}
+	if got, want := stats.Repositories[0].WeeklyCommits, []int{3, 0, 7}; !reflect.DeepEqual(got, want) {
+		t.Errorf("WeeklyCommits = %v, want %v", got, want)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("commit activity calls = %d, want 2", got)
+	}
+}
+
+// TestWeeklyCommitsComputing Check that the repositories still computing are retried together, then left out of the cache.
+func TestWeeklyCommitsComputing(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	f.handleJSON("GET /users/octocat/repos", []map[string]interface{}{
+		repoJSON("octocat", "a", 1), repoJSON("octocat", "b", 1), repoJSON("octocat", "c", 1),
+	})
+	f.handle("GET /repos/octocat/{repo}/stats/commit_activity", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusAccepted, map[string]interface{}{})
+	})
+	g := newTestGStats(t, f, Config{CommitActivityRetries: 2, CommitActivityRetryDelay: 100 * time.Millisecond})
+	opts := IncludeOptions{IncludeRepos: true, IncludeCommitActivity: true, IncludeFirstNRepos: AllRepos}
+
+	start := time.Now()
+	stats, _, err := g.cachedStats(context.Background(), "octocat", opts)
+	if err != nil {
+		t.Fatalf("cachedStats: %v", err)
+	}
+	// Two rounds for the three repositories, one after another would take 600ms
+	if elapsed := time.Since(start); elapsed > 400*time.Millisecond {
+		t.Errorf("took %v, want a single delay per round", elapsed)
+	}
+	if calls := f.count("GET /repos/octocat/{repo}/stats/commit_activity"); calls != 9 {
+		t.Errorf("commit activity calls = %d, want 9", calls)
+	}
+	if !stats.Partial || stats.Repositories[0].WeeklyCommits != nil {
+		t.Errorf("Partial = %v, WeeklyCommits = %v, want partial without them", stats.Partial, stats.Repositories[0].WeeklyCommits)
+	}
+
+	if _, found := g.cache.Get(g.cacheKey("octocat", opts)); found {
+		t.Error("stats without the commit activity cached")
+	}
+}
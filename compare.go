@@ -0,0 +1,89 @@
+package githubstats
+
+import (
+	"net/http"
+	"sync"
+)
+
+type StatsDiff struct {
+	Followers    int `json:"followers"`
+	TotalStars   int `json:"total_stars"`
+	Repositories int `json:"repositories"`
+}
+
+type Comparison struct {
+	A    GitHubStats `json:"a"`
+	B    GitHubStats `json:"b"`
+	Diff StatsDiff   `json:"diff"`
+}
+
+// compareHandler Handle the requests comparing two users side by side.
+/*
+ * The diff is a minus b. Followers and stars are always included so the diff is meaningful.
+ *
+ * @param w http.ResponseWriter - The response writer
+ * @param r *http.Request - The request
+ * @return void
+ */
+func (g *GStats) compareHandler(w http.ResponseWriter, r *http.Request) {
+	if !g.checkStrictParams(w, r, true, compareParams) {
+		return
+	}
+	query := r.URL.Query()
+	usernames := [2]string{query.Get("a"), query.Get("b")}
+
+	if usernames[0] == "" || usernames[1] == "" {
+		writeError(w, r, "Both a and b usernames are required", http.StatusBadRequest)
+		return
+	}
+
+	// Check the request limit
+	if limiter := g.limiterFor(g.config.ComparePath); !g.admit(r.Context(), limiter) {
+		g.writeRateLimited(w, r, limiter)
+		return
+	}
+
+	opts, err := g.parseIncludeOptions(query)
+	if err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	opts.IncludeFollowers = true
+	opts.IncludeStars = true
+	if err := validateIncludeOptions(opts); err != nil {
+		writeError(w, r, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	var results [2]GitHubStats
+	var errs [2]error
+	var wg sync.WaitGroup
+	for i, username := range usernames {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _, errs[i] = g.cachedStats(r.Context(), username, opts)
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			g.writeStatsError(w, r, err)
+			return
+		}
+	}
+
+	a, b := g.formatStats(results[0]), g.formatStats(results[1])
+	comparison := Comparison{
+		A: a,
+		B: b,
+		Diff: StatsDiff{
+			Followers:    a.Followers - b.Followers,
+			TotalStars:   a.TotalStars - b.TotalStars,
+			Repositories: a.TotalRepositories - b.TotalRepositories,
+		},
+	}
+
+	writeData(w, r, a.Username+"-vs-"+b.Username, g.envelope(comparison))
+}
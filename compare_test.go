@@ -0,0 +1,52 @@
+package githubstats
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestCompare Check the side by side stats of two users and their difference.
+func TestCompare(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("alice", map[string]interface{}{"followers": 10, "public_repos": 3})
+	f.handleUser("bob", map[string]interface{}{"followers": 4, "public_repos": 1})
+	f.handleJSON("GET /users/alice/repos", []map[string]interface{}{repoJSON("alice", "a", 5), repoJSON("alice", "b", 5)})
+	f.handleJSON("GET /users/bob/repos", []map[string]interface{}{repoJSON("bob", "c", 2)})
+	g := newTestGStats(t, f, Config{})
+
+	rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/compare?a=alice&b=bob", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	var comparison Comparison
+	if err := json.Unmarshal(rec.Body.Bytes(), &comparison); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if comparison.A.Username != "alice" || comparison.B.Username != "bob" {
+		t.Errorf("users = %s, %s", comparison.A.Username, comparison.B.Username)
+	}
+	want := StatsDiff{Followers: 6, TotalStars: 8, Repositories: 2}
+	if comparison.Diff != want {
+		t.Errorf("Diff = %+v, want %+v", comparison.Diff, want)
+	}
+
+	if rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/compare?a=alice", nil)); rec.Code != http.StatusBadRequest {
+		t.Errorf("status without b = %d, want 400", rec.Code)
+	}
+}
+
+// TestInstancesHaveTheirOwnRoutes Check that two instances with the same paths can be set up in one process.
+func TestInstancesHaveTheirOwnRoutes(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("alice", nil)
+	first := newTestGStats(t, f, Config{})
+	second := newTestGStats(t, f, Config{})
+
+	for _, g := range []*GStats{first, second} {
+		if rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=alice", nil)); rec.Code != http.StatusOK {
+			t.Errorf("status = %d, want 200", rec.Code)
+		}
+	}
+}
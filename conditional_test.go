@@ -0,0 +1,41 @@
+package githubstats
+
+import (
+	"net/http"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+// TestConditionalRequests Check that a repository response answered 304 is reused from the stored one.
+func TestConditionalRequests(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	f.handleJSON("GET /users/octocat/repos", []map[string]interface{}{repoJSON("octocat", "hello", 1)})
+	const lastModified = "Wed, 14 Oct 2026 10:00:00 GMT"
+	var notModified atomic.Int32
+	f.handle("GET /repos/octocat/hello/contributors", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-Modified-Since") == lastModified {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Last-Modified", lastModified)
+		writeJSON(w, http.StatusOK, []map[string]interface{}{{"login": "alice", "contributions": 4}})
+	})
+	g := newTestGStats(t, f, Config{ConditionalRequests: true})
+
+	opts := IncludeOptions{IncludeRepos: true, IncludeContributors: true, IncludeFirstNRepos: AllRepos}
+	for i := 0; i < 2; i++ {
+		stats, err := g.GetGitHubStats("octocat", opts)
+		if err != nil {
+			t.Fatalf("fetch %d: %v", i, err)
+		}
+		if got, want := stats.Repositories[0].Contributors, map[string]int{"alice": 4}; !reflect.DeepEqual(got, want) {
+			t.Errorf("fetch %d: Contributors = %v, want %v", i, got, want)
+		}
+	}
+	if got := notModified.Load(); got != 1 {
+		t.Errorf("304 answers = %d, want 1", got)
+	}
+}
@@ -0,0 +1,57 @@
+package githubstats
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/github"
+)
+
+// fetchContributedRepos Fetch the repositories the user opened pull requests on without owning them.
+/*
+ * @param ctx context.Context - The context
+ * @param client *github.Client - The GitHub client
+ * @param username string - The username
+ * @param limit int - The maximum number of repositories
+ * @return []RepoStats, error - The repositories, the error (ErrInvalidUsername for a username that isn't a login)
+ */
+func fetchContributedRepos(ctx context.Context, client *github.Client, username string, limit int) ([]RepoStats, error) {
+	// A space or a colon would add qualifiers to the search
+	if !usernamePattern.MatchString(username) {
+		return nil, ErrInvalidUsername
+	}
+	query := fmt.Sprintf("type:pr author:%s -user:%s", username, username)
+	result, _, err := client.Search.Issues(ctx, query, &github.SearchOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Several pull requests can target the same repository
+	var fullNames []string
+	seen := make(map[string]bool)
+	for _, issue := range result.Issues {
+		_, fullName, found := strings.Cut(issue.GetRepositoryURL(), "/repos/")
+		if !found || seen[fullName] {
+			continue
+		}
+		seen[fullName] = true
+		fullNames = append(fullNames, fullName)
+		if len(fullNames) >= limit {
+			break
+		}
+	}
+
+	repos := []RepoStats{}
+	for _, fullName := range fullNames {
+		owner, name, _ := strings.Cut(fullName, "/")
+		repo, _, err := client.Repositories.Get(ctx, owner, name)
+		if err != nil {
+			return nil, err
+		}
+		repos = append(repos, newRepoStats(repo))
+	}
+	return repos, nil
+}
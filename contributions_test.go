@@ -0,0 +1,54 @@
+package githubstats
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// TestExternalContributions Check that the repositories of the user's pull requests are listed once each, up to MaxContributedRepos.
+func TestExternalContributions(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	var query string
+	f.handle("GET /search/issues", func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Get("q")
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"total_count": 3,
+			"items": []map[string]interface{}{
+				{"repository_url": "https://api.github.com/repos/rails/rails"},
+				{"repository_url": "https://api.github.com/repos/rails/rails"},
+				{"repository_url": "https://api.github.com/repos/golang/go"},
+				{"repository_url": "https://api.github.com/repos/nodejs/node"},
+			},
+		})
+	})
+	f.handleJSON("GET /repos/rails/rails", repoJSON("rails", "rails", 50000))
+	f.handleJSON("GET /repos/golang/go", repoJSON("golang", "go", 120000))
+	g := newTestGStats(t, f, Config{MaxContributedRepos: 2})
+
+	stats, err := g.GetGitHubStats("octocat", IncludeOptions{IncludeExternalContributions: true})
+	if err != nil {
+		t.Fatalf("GetGitHubStats: %v", err)
+	}
+	if query != "type:pr author:octocat -user:octocat" {
+		t.Errorf("search query = %q", query)
+	}
+	if len(stats.ContributedRepositories) != 2 || stats.ContributedRepositories[0].Name != "rails/rails" || stats.ContributedRepositories[1].Name != "golang/go" {
+		t.Errorf("ContributedRepositories = %+v, want rails/rails and golang/go", stats.ContributedRepositories)
+	}
+}
+
+// TestExternalContributionsInjection Check that a username adding search qualifiers is rejected before searching.
+func TestExternalContributionsInjection(t *testing.T) {
+	f := newFakeGitHub(t)
+	g := newTestGStats(t, f, Config{})
+
+	if _, err := fetchContributedRepos(context.Background(), g.client, "octocat is:private", 10); !errors.Is(err, ErrInvalidUsername) {
+		t.Errorf("err = %v, want ErrInvalidUsername", err)
+	}
+	if calls := f.count("GET /search/issues"); calls != 0 {
+		t.Errorf("search calls = %d, want 0", calls)
+	}
+}
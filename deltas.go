@@ -0,0 +1,44 @@
+package githubstats
+
+type RepoDelta struct {
+	Stars int `json:"stars"`
+	Forks int `json:"forks"`
+}
+
+type StatsDeltas struct {
+	Followers    int                  `json:"followers"`
+	TotalStars   int                  `json:"total_stars"`
+	Repositories map[string]RepoDelta `json:"repositories,omitempty"` // By repository name, only the ones in both values
+}
+
+// computeDeltas Compute the changes between the previous cached stats and the new ones.
+/*
+ * @param prev GitHubStats - The previous stats
+ * @param next GitHubStats - The new stats
+ * @return *StatsDeltas - The deltas
+ */
+func computeDeltas(prev GitHubStats, next GitHubStats) *StatsDeltas {
+	deltas := &StatsDeltas{
+		Followers:  next.Followers - prev.Followers,
+		TotalStars: next.TotalStars - prev.TotalStars,
+	}
+
+	previous := make(map[string]RepoStats, len(prev.Repositories))
+	for _, repo := range prev.Repositories {
+		previous[repo.Name] = repo
+	}
+	for _, repo := range next.Repositories {
+		old, found := previous[repo.Name]
+		if !found {
+			continue
+		}
+		if deltas.Repositories == nil {
+			deltas.Repositories = make(map[string]RepoDelta)
+		}
+		deltas.Repositories[repo.Name] = RepoDelta{
+			Stars: repo.Stars - old.Stars,
+			Forks: repo.Forks - old.Forks,
+		}
+	}
+	return deltas
+}
@@ -0,0 +1,40 @@
+package githubstats
+
+import (
+	"net/http"
+	"strings"
+)
+
+// setDownload Serve the response as an attachment when the download query parameter is true.
+/*
+ * @param w http.ResponseWriter - The response writer
+ * @param r *http.Request - The request
+ * @param name string - The file name, without its extension
+ * @param ext string - The file extension
+ * @return void
+ */
+func setDownload(w http.ResponseWriter, r *http.Request, name string, ext string) {
+	if r.URL.Query().Get("download") != "true" {
+		return
+	}
+	w.Header().Set("Content-Disposition", `attachment; filename="`+safeFilename(name)+"."+ext+`"`)
+}
+
+// safeFilename Strip the characters that are not safe in a file name or a header value.
+/*
+ * @param name string - The name
+ * @return string - The safe name
+ */
+func safeFilename(name string) string {
+	safe := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		}
+		return -1
+	}, name)
+	if safe == "" {
+		return "stats"
+	}
+	return safe
+}
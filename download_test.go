@@ -0,0 +1,41 @@
+package githubstats
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestDownload Check that download=true serves the stats as an attachment named after the user.
+func TestDownload(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	g := newTestGStats(t, f, Config{})
+
+	rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat&download=true", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	if got, want := rec.Header().Get("Content-Disposition"), `attachment; filename="octocat-stats.json"`; got != want {
+		t.Errorf("Content-Disposition = %q, want %q", got, want)
+	}
+
+	rec = serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat", nil))
+	if got := rec.Header().Get("Content-Disposition"); got != "" {
+		t.Errorf("Content-Disposition = %q without download, want none", got)
+	}
+}
+
+// TestSafeFilename Check that the characters unsafe in a header value are stripped.
+func TestSafeFilename(t *testing.T) {
+	for name, want := range map[string]string{
+		"octocat-stats":    "octocat-stats",
+		`a"b\r\n; x=../..`: "abrnx",
+		"":                 "stats",
+		"\"\r\n":           "stats",
+	} {
+		if got := safeFilename(name); got != want {
+			t.Errorf("safeFilename(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
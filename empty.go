@@ -0,0 +1,36 @@
+package githubstats
+
+import (
+	"errors"
+	"regexp"
+	"time"
+)
+
+// EmptyIncludeMode values.
+const (
+	EmptyIncludeFetch    = "fetch"    // Fetch the user anyway, only the base fields are set
+	EmptyIncludeReject   = "reject"   // Answer 400 asking for at least one section
+	EmptyIncludeUsername = "username" // Answer the username alone, without calling GitHub
+)
+
+// ErrInvalidUsername is returned when a username can't be a GitHub login.
+var ErrInvalidUsername = errors.New("invalid username")
+
+// usernamePattern GitHub logins: alphanumeric or single hyphens, up to 39 characters, not starting with a hyphen.
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]{0,38}$`)
+
+// isEmpty Check if the options select no section at all.
+/*
+ * The parameters only refining a section, like include_first_n_repos or top_by, don't count.
+ *
+ * @return bool - The result
+ */
+func (opts IncludeOptions) isEmpty() bool {
+	opts.IncludeFirstNRepos = 0
+	opts.HeavyMinStars = 0
+	opts.MinContributions = 0
+	opts.PushedSince = time.Time{}
+	opts.TopBy = ""
+	opts.unknownPreset = ""
+	return opts == IncludeOptions{}
+}
@@ -0,0 +1,57 @@
+package githubstats
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestEmptyIncludeMode Check the answer to a request without any section in each EmptyIncludeMode.
+func TestEmptyIncludeMode(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+
+	rec := serveRequest(newTestGStats(t, f, Config{EmptyIncludeMode: EmptyIncludeUsername}), httptest.NewRequest(http.MethodGet, "/stats?username=octocat", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("username mode status = %d", rec.Code)
+	}
+	var stats GitHubStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if stats.Username != "octocat" {
+		t.Errorf("Username = %q, want octocat", stats.Username)
+	}
+	if calls := f.totalCalls(); calls != 0 {
+		t.Errorf("GitHub calls in username mode = %d, want 0", calls)
+	}
+
+	g := newTestGStats(t, f, Config{EmptyIncludeMode: EmptyIncludeReject})
+	if rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat&include_first_n_repos=3", nil)); rec.Code != http.StatusBadRequest {
+		t.Errorf("reject mode status = %d, want 400", rec.Code)
+	}
+	if rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat&include_followers=true", nil)); rec.Code != http.StatusOK {
+		t.Errorf("reject mode status with a section = %d, want 200", rec.Code)
+	}
+
+	rec = serveRequest(newTestGStats(t, f, Config{}), httptest.NewRequest(http.MethodGet, "/stats?username=octocat", nil))
+	if rec.Code != http.StatusOK || f.count("GET /users/octocat") != 2 {
+		t.Errorf("fetch mode status = %d with %d user calls, want 200 after fetching", rec.Code, f.count("GET /users/octocat"))
+	}
+}
+
+// TestInvalidUsername Check that a username that can't be a GitHub login is rejected without calling GitHub.
+func TestInvalidUsername(t *testing.T) {
+	f := newFakeGitHub(t)
+	g := newTestGStats(t, f, Config{EmptyIncludeMode: EmptyIncludeUsername})
+
+	for _, username := range []string{"-octocat", "octo_cat", "a%2Fb", "abcdefghijklmnopqrstuvwxyzabcdefghijklmn"} {
+		if rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username="+username, nil)); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", username, rec.Code)
+		}
+	}
+	if calls := f.totalCalls(); calls != 0 {
+		t.Errorf("GitHub calls = %d, want 0", calls)
+	}
+}
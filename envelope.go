@@ -0,0 +1,21 @@
+package githubstats
+
+// SchemaVersion Version of the response schema, incremented on every breaking change to GitHubStats.
+const SchemaVersion = 1
+
+type Envelope struct {
+	SchemaVersion int         `json:"schema_version"`
+	Data          interface{} `json:"data"`
+}
+
+// envelope Wrap a response body in an Envelope when EnvelopeResponses is set.
+/*
+ * @param data interface{} - The response body
+ * @return interface{} - The body to encode
+ */
+func (g *GStats) envelope(data interface{}) interface{} {
+	if !g.config.EnvelopeResponses {
+		return data
+	}
+	return Envelope{SchemaVersion: SchemaVersion, Data: data}
+}
@@ -0,0 +1,37 @@
+package githubstats
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestEnvelope Check that the enveloped responses carry SchemaVersion, and the others are left bare.
+func TestEnvelope(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", map[string]interface{}{"followers": 3})
+
+	g := newTestGStats(t, f, Config{EnvelopeResponses: true})
+	rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat&include_followers=true", nil))
+	var envelope struct {
+		SchemaVersion int         `json:"schema_version"`
+		Data          GitHubStats `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &envelope); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if envelope.SchemaVersion != SchemaVersion || envelope.Data.Followers != 3 {
+		t.Errorf("envelope = %+v, want schema version %d and the stats", envelope, SchemaVersion)
+	}
+
+	g = newTestGStats(t, f, Config{})
+	rec = serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat&include_followers=true", nil))
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if _, ok := body["schema_version"]; ok {
+		t.Error("schema_version set without EnvelopeResponses")
+	}
+}
@@ -0,0 +1,72 @@
+package githubstats
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+var errorPageTemplate = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Status}} {{.StatusText}}</title>
+<style>body{font-family:sans-serif;max-width:40em;margin:4em auto;color:#24292f}h1{font-weight:normal}</style>
+</head>
+<body>
+<h1>{{.Status}} {{.StatusText}}</h1>
+<p>{{.Message}}</p>
+</body>
+</html>
+`))
+
+type errorPage struct {
+	Status     int
+	StatusText string
+	Message    string
+}
+
+// writeError Write an error in the format the client asked for: an HTML page, JSON, or plain text by default.
+/*
+ * @param w http.ResponseWriter - The response writer
+ * @param r *http.Request - The request
+ * @param message string - The error message
+ * @param status int - The status code
+ * @return void
+ */
+func writeError(w http.ResponseWriter, r *http.Request, message string, status int) {
+	switch {
+	case wantsHTML(r):
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(status)
+		errorPageTemplate.Execute(w, errorPage{
+			Status:     status,
+			StatusText: http.StatusText(status),
+			Message:    message,
+		})
+	case strings.Contains(r.Header.Get("Accept"), "application/json"):
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": message})
+	default:
+		http.Error(w, message, status)
+	}
+}
+
+// wantsHTML Check if the client asked for HTML with format=html or an Accept header preferring text/html.
+/*
+ * @param r *http.Request - The request
+ * @return bool - The result
+ */
+func wantsHTML(r *http.Request) bool {
+	if r.URL.Query().Get("format") == "html" {
+		return true
+	}
+	accept := r.Header.Get("Accept")
+	html := strings.Index(accept, "text/html")
+	jsonIndex := strings.Index(accept, "application/json")
+	return html >= 0 && (jsonIndex < 0 || html < jsonIndex)
+}
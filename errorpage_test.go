@@ -0,0 +1,43 @@
+package githubstats
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestErrorPageFormats Check that the errors are rendered as HTML, JSON or plain text as the client asks.
+func TestErrorPageFormats(t *testing.T) {
+	f := newFakeGitHub(t)
+	g := newTestGStats(t, f, Config{})
+
+	tests := []struct {
+		name        string
+		target      string
+		accept      string
+		contentType string
+		body        string
+	}{
+		{"format=html", "/stats?username=ghost&format=html", "", "text/html; charset=utf-8", "<h1>404 Not Found</h1>"},
+		{"Accept: text/html", "/stats?username=ghost", "text/html,application/json", "text/html; charset=utf-8", "<p>User not found</p>"},
+		{"Accept: application/json", "/stats?username=ghost", "application/json", "application/json", `{"error":"User not found"}`},
+		{"plain text", "/stats?username=ghost", "", "text/plain; charset=utf-8", "User not found"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+		if tt.accept != "" {
+			r.Header.Set("Accept", tt.accept)
+		}
+		rec := serveRequest(g, r)
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("%s: status = %d, want 404", tt.name, rec.Code)
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != tt.contentType {
+			t.Errorf("%s: Content-Type = %q, want %q", tt.name, ct, tt.contentType)
+		}
+		if !strings.Contains(rec.Body.String(), tt.body) {
+			t.Errorf("%s: body = %q, want %q", tt.name, rec.Body, tt.body)
+		}
+	}
+}
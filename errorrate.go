@@ -0,0 +1,105 @@
+package githubstats
+
+import (
+	"sync"
+	"time"
+)
+
+// minErrorRateCalls Calls needed in the window before the error ratio is trusted, so a single failure isn't 100%.
+const minErrorRateCalls = 10
+
+// errorRateBuckets Number of buckets the window is split into.
+const errorRateBuckets = 10
+
+type errorBucket struct {
+	start  time.Time
+	calls  int
+	failed int
+}
+
+type ErrorRate struct {
+	mu      sync.Mutex
+	window  time.Duration
+	buckets []errorBucket // Oldest first, none older than the window
+}
+
+// NewErrorRate Create a rolling GitHub error ratio.
+/*
+ * @param window time.Duration - The period the ratio is computed over
+ * @return *ErrorRate - The error ratio
+ */
+func NewErrorRate(window time.Duration) *ErrorRate {
+	return &ErrorRate{window: window}
+}
+
+// Record Record the outcome of a GitHub call.
+/*
+ * @param failed bool - Whether GitHub failed
+ * @return void
+ */
+func (e *ErrorRate) Record(failed bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	e.prune(now)
+	width := e.window / errorRateBuckets
+	if len(e.buckets) == 0 || now.Sub(e.buckets[len(e.buckets)-1].start) >= width {
+		e.buckets = append(e.buckets, errorBucket{start: now})
+	}
+	bucket := &e.buckets[len(e.buckets)-1]
+	bucket.calls++
+	if failed {
+		bucket.failed++
+	}
+}
+
+// Ratio Get the ratio of failed GitHub calls over the window.
+/*
+ * @return float64, bool - The ratio, whether enough calls were made to compute it
+ */
+func (e *ErrorRate) Ratio() (float64, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.prune(time.Now())
+	calls, failed := 0, 0
+	for _, bucket := range e.buckets {
+		calls += bucket.calls
+		failed += bucket.failed
+	}
+	if calls < minErrorRateCalls {
+		return 0, false
+	}
+	return float64(failed) / float64(calls), true
+}
+
+// Failed Check if a GitHub call failed within the window.
+/*
+ * @return bool - The result
+ */
+func (e *ErrorRate) Failed() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.prune(time.Now())
+	for _, bucket := range e.buckets {
+		if bucket.failed > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// prune Drop the buckets older than the window, the lock must be held.
+/*
+ * @param now time.Time - The current time
+ * @return void
+ */
+func (e *ErrorRate) prune(now time.Time) {
+	i := 0
+	for i < len(e.buckets) && now.Sub(e.buckets[i].start) >= e.window {
+		i++
+	}
+	e.buckets = e.buckets[i:]
+}
@@ -0,0 +1,167 @@
+package githubstats
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+)
+
+const maxExprLength = 256
+
+// errDivisionByZero is returned by evalExpr when a divisor is 0.
+var errDivisionByZero = errors.New("division by zero")
+
+// exprFields The numeric fields an expression can refer to.
+var exprFields = map[string]func(GitHubStats) float64{
+	"followers":      func(s GitHubStats) float64 { return float64(s.Followers) },
+	"following":      func(s GitHubStats) float64 { return float64(s.Following) },
+	"total_stars":    func(s GitHubStats) float64 { return float64(s.TotalStars) },
+	"current_streak": func(s GitHubStats) float64 { return float64(s.CurrentStreak) },
+	"longest_streak": func(s GitHubStats) float64 { return float64(s.LongestStreak) },
+}
+
+// parseExpr Parse an arithmetic expression, only allowing numbers, known fields, + - * / and parentheses.
+/*
+ * The expression is only walked by evalExpr, nothing is ever executed.
+ *
+ * @param src string - The expression
+ * @return ast.Expr, error - The expression, the error
+ */
+func parseExpr(src string) (ast.Expr, error) {
+	if len(src) > maxExprLength {
+		return nil, fmt.Errorf("expression longer than %d characters", maxExprLength)
+	}
+	expr, err := parser.ParseExpr(src)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression: %v", err)
+	}
+
+	var walkErr error
+	ast.Inspect(expr, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case nil, *ast.ParenExpr:
+		case *ast.BasicLit:
+			if n.Kind != token.INT && n.Kind != token.FLOAT {
+				walkErr = fmt.Errorf("unsupported literal %s", n.Value)
+			}
+		case *ast.Ident:
+			if _, ok := exprFields[n.Name]; !ok {
+				walkErr = fmt.Errorf("unknown field %s", n.Name)
+			}
+		case *ast.BinaryExpr:
+			switch n.Op {
+			case token.ADD, token.SUB, token.MUL:
+			case token.QUO:
+				// A divisor without any field is 0 whatever the user, unlike followers for a user without any
+				if isConstant(n.Y) {
+					if y, err := evalExpr(n.Y, GitHubStats{}); err != nil || y == 0 {
+						walkErr = errDivisionByZero
+					}
+				}
+			default:
+				walkErr = fmt.Errorf("unsupported operator %s", n.Op)
+			}
+		case *ast.UnaryExpr:
+			if n.Op != token.SUB && n.Op != token.ADD {
+				walkErr = fmt.Errorf("unsupported operator %s", n.Op)
+			}
+		default:
+			walkErr = errors.New("unsupported expression")
+		}
+		return walkErr == nil
+	})
+	if walkErr != nil {
+		return nil, walkErr
+	}
+	return expr, nil
+}
+
+// evalExpr Evaluate an expression parsed by parseExpr over the stats.
+/*
+ * @param expr ast.Expr - The expression
+ * @param stats GitHubStats - The stats
+ * @return float64, error - The value, the error
+ */
+func evalExpr(expr ast.Expr, stats GitHubStats) (float64, error) {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return evalExpr(e.X, stats)
+	case *ast.BasicLit:
+		return strconv.ParseFloat(e.Value, 64)
+	case *ast.Ident:
+		return exprFields[e.Name](stats), nil
+	case *ast.UnaryExpr:
+		x, err := evalExpr(e.X, stats)
+		if e.Op == token.SUB {
+			x = -x
+		}
+		return x, err
+	case *ast.BinaryExpr:
+		x, err := evalExpr(e.X, stats)
+		if err != nil {
+			return 0, err
+		}
+		y, err := evalExpr(e.Y, stats)
+		if err != nil {
+			return 0, err
+		}
+		switch e.Op {
+		case token.ADD:
+			return x + y, nil
+		case token.SUB:
+			return x - y, nil
+		case token.MUL:
+			return x * y, nil
+		default:
+			if y == 0 {
+				return 0, errDivisionByZero
+			}
+			return x / y, nil
+		}
+	}
+	return 0, errors.New("unsupported expression")
+}
+
+// isConstant Check if an expression refers to no field.
+/*
+ * @param expr ast.Expr - The expression
+ * @return bool - The result
+ */
+func isConstant(expr ast.Expr) bool {
+	constant := true
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if _, ok := n.(*ast.Ident); ok {
+			constant = false
+		}
+		return constant
+	})
+	return constant
+}
+
+// computeExpr Set the Computed field of the stats from the expression.
+/*
+ * A division by a field that is 0 for this user leaves Computed null with a warning, the request is valid.
+ *
+ * @param stats GitHubStats - The stats
+ * @param expr ast.Expr - The expression (nothing is computed if nil)
+ * @return GitHubStats, error - The stats, the error
+ */
+func computeExpr(stats GitHubStats, expr ast.Expr) (GitHubStats, error) {
+	if expr == nil {
+		return stats, nil
+	}
+	value, err := evalExpr(expr, stats)
+	if errors.Is(err, errDivisionByZero) {
+		// The warnings of the cached stats are shared
+		stats.Warnings = append(append([]string(nil), stats.Warnings...), "expr: division by zero, computed is null")
+		return stats, nil
+	}
+	if err != nil {
+		return stats, err
+	}
+	stats.Computed = &value
+	return stats, nil
+}
@@ -0,0 +1,84 @@
+package githubstats
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestExprRatio Check that an expression is evaluated over the stats.
+func TestExprRatio(t *testing.T) {
+	expr, err := parseExpr("(total_stars + 2) / followers * 10")
+	if err != nil {
+		t.Fatalf("parseExpr: %v", err)
+	}
+	value, err := evalExpr(expr, GitHubStats{TotalStars: 18, Followers: 4})
+	if err != nil || value != 50 {
+		t.Errorf("evalExpr = %v, %v, want 50", value, err)
+	}
+
+	if _, err := evalExpr(expr, GitHubStats{}); !errors.Is(err, errDivisionByZero) {
+		t.Errorf("err = %v, want errDivisionByZero", err)
+	}
+}
+
+// TestExprStarsPerFollower Check the total_stars/followers ratio of a response, null for a user without followers.
+func TestExprStarsPerFollower(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", map[string]interface{}{"followers": 4})
+	f.handleUser("loner", map[string]interface{}{"followers": 0})
+	f.handleJSON("GET /users/octocat/repos", []map[string]interface{}{repoJSON("octocat", "hello", 10)})
+	f.handleJSON("GET /users/loner/repos", []map[string]interface{}{repoJSON("loner", "hello", 10)})
+	g := newTestGStats(t, f, Config{})
+
+	get := func(username string) (int, GitHubStats) {
+		rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username="+username+"&include_followers=true&include_stars=true&expr=total_stars/followers", nil))
+		var stats GitHubStats
+		if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+			t.Fatalf("decode: %v (body %s)", err, rec.Body)
+		}
+		return rec.Code, stats
+	}
+
+	if code, stats := get("octocat"); code != http.StatusOK || stats.Computed == nil || *stats.Computed != 2.5 {
+		t.Errorf("octocat: %d, Computed = %v, want 2.5", code, stats.Computed)
+	}
+	code, stats := get("loner")
+	if code != http.StatusOK || stats.Computed != nil {
+		t.Errorf("loner: %d, Computed = %v, want 200 and null", code, stats.Computed)
+	}
+	if len(stats.Warnings) != 1 {
+		t.Errorf("Warnings = %v, want the division by zero", stats.Warnings)
+	}
+}
+
+// TestExprRejected Check that anything but arithmetic over the known fields is rejected.
+func TestExprRejected(t *testing.T) {
+	for _, src := range []string{"os.Exit(1)", "len(followers)", "unknown + 1", `"text"`, "followers % 2", "followers[0]"} {
+		if _, err := parseExpr(src); err == nil {
+			t.Errorf("parseExpr(%q) accepted", src)
+		}
+	}
+}
+
+// TestExprHandler Check the computed field of a response.
+func TestExprHandler(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", map[string]interface{}{"followers": 4, "following": 2})
+	g := newTestGStats(t, f, Config{})
+
+	rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat&include_followers=true&include_following=true&expr=followers/following", nil))
+	var stats GitHubStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("decode: %v (body %s)", err, rec.Body)
+	}
+	if stats.Computed == nil || *stats.Computed != 2 {
+		t.Errorf("Computed = %v, want 2", stats.Computed)
+	}
+
+	if rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat&expr=followers/0", nil)); rec.Code != http.StatusBadRequest {
+		t.Errorf("status of a division by zero = %d, want 400", rec.Code)
+	}
+}
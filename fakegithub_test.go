@@ -0,0 +1,293 @@
+package githubstats
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+	"golang.org/x/oauth2"
+)
+
+// fakeGitHub A fake GitHub API counting the calls of each route, the unknown routes answer 404.
+type fakeGitHub struct {
+	server *httptest.Server
+	mux    *http.ServeMux
+
+	mu    sync.Mutex
+	calls map[string]int // Calls by route pattern
+	total int
+}
+
+// newFakeGitHub Start a fake GitHub API, closed at the end of the test.
+/*
+ * @param t *testing.T - The test
+ * @return *fakeGitHub - The fake API
+ */
+func newFakeGitHub(t *testing.T) *fakeGitHub {
+	t.Helper()
+	f := &fakeGitHub{
+		mux:   http.NewServeMux(),
+		calls: make(map[string]int),
+	}
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.total++
+		f.mu.Unlock()
+		f.mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+// handle Register a route, counting its calls.
+/*
+ * @param pattern string - The ServeMux pattern, e.g. "GET /users/{user}"
+ * @param h http.HandlerFunc - The handler
+ * @return void
+ */
+func (f *fakeGitHub) handle(pattern string, h http.HandlerFunc) {
+	f.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.calls[pattern]++
+		f.mu.Unlock()
+		h(w, r)
+	})
+}
+
+// handleJSON Register a route answering a fixed JSON body.
+/*
+ * @param pattern string - The ServeMux pattern
+ * @param v interface{} - The body
+ * @return void
+ */
+func (f *fakeGitHub) handleJSON(pattern string, v interface{}) {
+	f.handle(pattern, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, v)
+	})
+}
+
+// handleUser Register the user route with the given payload fields.
+/*
+ * @param login string - The login
+ * @param fields map[string]interface{} - Extra fields of the user payload, e.g. "followers" (nil for none)
+ * @return void
+ */
+func (f *fakeGitHub) handleUser(login string, fields map[string]interface{}) {
+	user := map[string]interface{}{
+		"login":        login,
+		"type":         AccountTypeUser,
+		"followers":    0,
+		"following":    0,
+		"public_repos": 0,
+	}
+	for name, value := range fields {
+		user[name] = value
+	}
+	f.handleJSON("GET /users/"+login, user)
+}
+
+// count Get the number of calls of a route.
+/*
+ * @param pattern string - The ServeMux pattern
+ * @return int - The number of calls
+ */
+func (f *fakeGitHub) count(pattern string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[pattern]
+}
+
+// totalCalls Get the number of calls received, the unknown routes included.
+/*
+ * @return int - The number of calls
+ */
+func (f *fakeGitHub) totalCalls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.total
+}
+
+// point Point a GitHub client at the fake API.
+/*
+ * @param client *github.Client - The client
+ * @return void
+ */
+func (f *fakeGitHub) point(client *github.Client) {
+	client.BaseURL, _ = url.Parse(f.server.URL + "/")
+}
+
+// userClient Create a client like withUserClient does for a caller's token, pointed at the fake API.
+/*
+ * @param g *GStats - The instance
+ * @param token string - The caller's token
+ * @return *github.Client - The client
+ */
+func (f *fakeGitHub) userClient(g *GStats, token string) *github.Client {
+	client := newGitHubClient(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}), g.config, g.transport).client
+	f.point(client)
+	return client
+}
+
+// newTestGStats Set up an instance whose GitHub clients call the fake API.
+/*
+ * @param t *testing.T - The test
+ * @param f *fakeGitHub - The fake API
+ * @param config Config - The configuration, Token defaults to "test-token"
+ * @return *GStats - The instance
+ */
+func newTestGStats(t *testing.T, f *fakeGitHub, config Config) *GStats {
+	t.Helper()
+	if config.Token == "" && len(config.Tokens) == 0 && config.TokenSource == nil {
+		config.Token = "test-token"
+	}
+	g, err := NewGStats(config)
+	if err != nil {
+		t.Fatalf("NewGStats: %v", err)
+	}
+	for _, tc := range g.clients.clients {
+		f.point(tc.client)
+	}
+	return g
+}
+
+// writeJSON Write a JSON response.
+/*
+ * @param w http.ResponseWriter - The response writer
+ * @param status int - The status code
+ * @param v interface{} - The body
+ * @return void
+ */
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// setNextPage Set the Link header pointing at the next page, as GitHub paginates.
+/*
+ * @param w http.ResponseWriter - The response writer
+ * @param r *http.Request - The request
+ * @param page int - The next page
+ * @return void
+ */
+func setNextPage(w http.ResponseWriter, r *http.Request, page int) {
+	next := *r.URL
+	query := next.Query()
+	query.Set("page", fmt.Sprint(page))
+	next.RawQuery = query.Encode()
+	w.Header().Set("Link", fmt.Sprintf(`<http://%s%s>; rel="next"`, r.Host, next.RequestURI()))
+}
+
+// serveRequest Serve a request with the routes of the instance.
+/*
+ * @param g *GStats - The instance
+ * @param r *http.Request - The request
+ * @return *httptest.ResponseRecorder - The response
+ */
+func serveRequest(g *GStats, r *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	g.Handler().ServeHTTP(rec, r)
+	return rec
+}
+
+// repoJSON Build the payload of a repository.
+/*
+ * @param owner string - The owner
+ * @param name string - The name
+ * @param stars int - The number of stars
+ * @return map[string]interface{} - The payload
+ */
+func repoJSON(owner string, name string, stars int) map[string]interface{} {
+	return map[string]interface{}{
+		"name":             name,
+		"full_name":        owner + "/" + name,
+		"owner":            map[string]interface{}{"login": owner},
+		"stargazers_count": stars,
+		"forks_count":      0,
+		"default_branch":   "main",
+	}
+}
+
+// redirectTransport Send every request to the fake API, whatever its host.
+type redirectTransport struct {
+	base   http.RoundTripper
+	target *url.URL
+}
+
+// RoundTrip Execute the request against the fake API.
+/*
+ * @param req *http.Request - The request
+ * @return *http.Response, error - The response, the error
+ */
+func (t *redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return t.base.RoundTrip(req)
+}
+
+// redirectDefaultTransport Send the requests of http.DefaultTransport to the fake API until the end of the test,
+// for the clients created by Connect before they can be pointed at it.
+/*
+ * @param t *testing.T - The test
+ * @param f *fakeGitHub - The fake API
+ * @return void
+ */
+func redirectDefaultTransport(t *testing.T, f *fakeGitHub) {
+	base := http.DefaultTransport
+	target, _ := url.Parse(f.server.URL)
+	http.DefaultTransport = &redirectTransport{base: base, target: target}
+	t.Cleanup(func() { http.DefaultTransport = base })
+}
+
+// startServer Run Connect in the background until the end of the test, waiting for the server to be set up.
+/*
+ * @param t *testing.T - The test
+ * @param g *GStats - The instance
+ * @param config Config - The configuration, listening on a free local port
+ * @return string - The base URL of the server
+ */
+func startServer(t *testing.T, g *GStats, config Config) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	addr := ln.Addr().(*net.TCPAddr)
+	ln.Close()
+	config.IP, config.Port = "127.0.0.1", fmt.Sprint(addr.Port)
+
+	done := make(chan error, 1)
+	go func() { done <- g.Connect(config) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		g.serverMu.Lock()
+		started := g.server != nil
+		g.serverMu.Unlock()
+		if started {
+			break
+		}
+		select {
+		case err := <-done:
+			t.Fatalf("Connect: %v", err)
+		default:
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the server did not start")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Cleanup(func() {
+		g.Shutdown()
+		<-done
+	})
+	return "http://" + addr.String()
+}
@@ -0,0 +1,230 @@
+package githubstats
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+type ActivityItem struct {
+	ID        string   `json:"id"`
+	Title     string   `json:"title"`
+	Link      string   `json:"link"`
+	CreatedAt JSONTime `json:"created_at"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Link    atomLink    `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	Title   string   `xml:"title"`
+	ID      string   `xml:"id"`
+	Updated string   `xml:"updated"`
+	Link    atomLink `xml:"link"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title   string  `xml:"title"`
+	Link    string  `xml:"link"`
+	GUID    rssGUID `xml:"guid"`
+	PubDate string  `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// feedHandler Handle the requests to get an RSS or Atom feed of the recent public activity of a user.
+/*
+ * The activity is fetched and cached like the include_activity section of the stats.
+ *
+ * @param w http.ResponseWriter - The response writer
+ * @param r *http.Request - The request
+ * @param username string - The username
+ * @param format string - The feed format, "rss" or "atom"
+ * @return void
+ */
+func (g *GStats) feedHandler(w http.ResponseWriter, r *http.Request, username string, format string) {
+	stats, _, err := g.cachedStats(r.Context(), username, IncludeOptions{IncludeActivity: true})
+	if err != nil {
+		g.writeStatsError(w, r, err)
+		return
+	}
+
+	var feed interface{}
+	contentType := "application/atom+xml; charset=utf-8"
+	if format == "rss" {
+		feed = rssFeedOf(stats.Username, stats.Activity)
+		contentType = "application/rss+xml; charset=utf-8"
+	} else {
+		feed = atomFeedOf(stats.Username, stats.Activity)
+	}
+	// Encoded first, so a failure can still be answered with an error status
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	if err := xml.NewEncoder(&buf).Encode(feed); err != nil {
+		writeError(w, r, "Failed to encode the feed", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	setDownload(w, r, username+"-feed", "xml")
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		log.Printf("githubstats: failed to write a feed: %v", err)
+	}
+}
+
+// atomFeedOf Build the Atom feed of the activity of a user.
+/*
+ * @param username string - The username
+ * @param activity []ActivityItem - The activity, most recent first
+ * @return atomFeed - The feed
+ */
+func atomFeedOf(username string, activity []ActivityItem) atomFeed {
+	feed := atomFeed{
+		Title:   fmt.Sprintf("%s's GitHub activity", username),
+		ID:      "https://github.com/" + username,
+		Updated: time.Now().UTC().Format(time.RFC3339),
+		Link:    atomLink{Href: "https://github.com/" + username},
+		Author:  atomAuthor{Name: username},
+	}
+	for _, item := range activity {
+		feed.Entries = append(feed.Entries, atomEntry{
+			Title:   item.Title,
+			ID:      "tag:github.com,2008:" + item.ID,
+			Updated: item.CreatedAt.UTC().Format(time.RFC3339),
+			Link:    atomLink{Href: item.Link},
+		})
+	}
+	if len(feed.Entries) > 0 {
+		feed.Updated = feed.Entries[0].Updated
+	}
+	return feed
+}
+
+// rssFeedOf Build the RSS 2.0 feed of the activity of a user.
+/*
+ * @param username string - The username
+ * @param activity []ActivityItem - The activity, most recent first
+ * @return rssFeed - The feed
+ */
+func rssFeedOf(username string, activity []ActivityItem) rssFeed {
+	feed := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:         fmt.Sprintf("%s's GitHub activity", username),
+			Link:          "https://github.com/" + username,
+			Description:   fmt.Sprintf("Recent pushes, stars and pull requests of %s", username),
+			LastBuildDate: time.Now().UTC().Format(time.RFC1123Z),
+		},
+	}
+	for _, item := range activity {
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:   item.Title,
+			Link:    item.Link,
+			GUID:    rssGUID{Value: "tag:github.com,2008:" + item.ID},
+			PubDate: item.CreatedAt.UTC().Format(time.RFC1123Z),
+		})
+	}
+	if len(feed.Channel.Items) > 0 {
+		feed.Channel.LastBuildDate = feed.Channel.Items[0].PubDate
+	}
+	return feed
+}
+
+// fetchActivity Fetch the recent public pushes, stars and pull requests of a user.
+/*
+ * @param ctx context.Context - The context
+ * @param client *github.Client - The GitHub client
+ * @param username string - The username
+ * @param limit int - The maximum number of entries
+ * @return []ActivityItem, error - The activity, most recent first, the error
+ */
+func fetchActivity(ctx context.Context, client *github.Client, username string, limit int) ([]ActivityItem, error) {
+	events, _, err := client.Activity.ListEventsPerformedByUser(ctx, username, true, &github.ListOptions{PerPage: 100})
+	if err != nil {
+		return nil, err
+	}
+
+	activity := []ActivityItem{}
+	for _, event := range events {
+		if len(activity) >= limit {
+			break
+		}
+		if item, ok := activityItem(username, event); ok {
+			activity = append(activity, item)
+		}
+	}
+	return activity, nil
+}
+
+// activityItem Convert a GitHub event to an activity entry.
+/*
+ * @param username string - The username
+ * @param event *github.Event - The event
+ * @return ActivityItem, bool - The entry, whether the event type is listed
+ */
+func activityItem(username string, event *github.Event) (ActivityItem, bool) {
+	repo := event.GetRepo().GetName()
+	item := ActivityItem{
+		ID:        event.GetID(),
+		Link:      "https://github.com/" + repo,
+		CreatedAt: JSONTime{Time: event.GetCreatedAt().UTC()},
+	}
+
+	payload, err := event.ParsePayload()
+	if err != nil {
+		return ActivityItem{}, false
+	}
+
+	switch p := payload.(type) {
+	case *github.PushEvent:
+		item.Title = fmt.Sprintf("%s pushed %d commit(s) to %s", username, p.GetSize(), repo)
+	case *github.WatchEvent:
+		item.Title = fmt.Sprintf("%s starred %s", username, repo)
+	case *github.PullRequestEvent:
+		item.Title = fmt.Sprintf("%s %s pull request #%d in %s", username, p.GetAction(), p.GetNumber(), repo)
+		if link := p.GetPullRequest().GetHTMLURL(); link != "" {
+			item.Link = link
+		}
+	default:
+		return ActivityItem{}, false
+	}
+	return item, true
+}
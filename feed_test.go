@@ -0,0 +1,91 @@
+package githubstats
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// handleEvents Serve a push, an unsupported fork and a star as the public events of octocat.
+/*
+ * @param f *fakeGitHub - The fake GitHub
+ * @return void
+ */
+func handleEvents(f *fakeGitHub) {
+	f.handleUser("octocat", nil)
+	f.handleJSON("GET /users/octocat/events/public", []map[string]interface{}{
+		{"id": "3", "type": "PushEvent", "repo": map[string]string{"name": "octocat/hello"}, "payload": map[string]int{"size": 2}, "created_at": "2024-03-02T10:00:00Z"},
+		{"id": "2", "type": "ForkEvent", "repo": map[string]string{"name": "octocat/fork"}, "payload": map[string]interface{}{}, "created_at": "2024-03-01T11:00:00Z"},
+		{"id": "1", "type": "WatchEvent", "repo": map[string]string{"name": "golang/go"}, "payload": map[string]string{"action": "started"}, "created_at": "2024-03-01T10:00:00Z"},
+	})
+}
+
+// TestFeed Check the Atom entries built from the public events, the unsupported ones left out.
+func TestFeed(t *testing.T) {
+	f := newFakeGitHub(t)
+	handleEvents(f)
+	g := newTestGStats(t, f, Config{})
+
+	rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat&format=atom", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/atom+xml; charset=utf-8" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	var feed atomFeed
+	if err := xml.Unmarshal(rec.Body.Bytes(), &feed); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(feed.Entries) != 2 {
+		t.Fatalf("entries = %+v, want 2", feed.Entries)
+	}
+	if feed.Entries[0].Title != "octocat pushed 2 commit(s) to octocat/hello" || feed.Entries[1].Title != "octocat starred golang/go" {
+		t.Errorf("titles = %q, %q", feed.Entries[0].Title, feed.Entries[1].Title)
+	}
+	if feed.Updated != "2024-03-02T10:00:00Z" {
+		t.Errorf("Updated = %q, want the latest entry", feed.Updated)
+	}
+}
+
+// TestFeedRSS Check the RSS 2.0 items built from the public events, the feed served from the cache once fetched.
+func TestFeedRSS(t *testing.T) {
+	f := newFakeGitHub(t)
+	handleEvents(f)
+	g := newTestGStats(t, f, Config{})
+
+	for i := 0; i < 2; i++ {
+		rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat&format=rss", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "application/rss+xml; charset=utf-8" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		var feed rssFeed
+		if err := xml.Unmarshal(rec.Body.Bytes(), &feed); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if feed.Version != "2.0" || len(feed.Channel.Items) != 2 {
+			t.Fatalf("feed = %+v, want 2 RSS 2.0 items", feed)
+		}
+		if item := feed.Channel.Items[0]; item.Title != "octocat pushed 2 commit(s) to octocat/hello" || item.PubDate != "Sat, 02 Mar 2024 10:00:00 +0000" {
+			t.Errorf("first item = %+v", item)
+		}
+	}
+	if calls := f.count("GET /users/octocat/events/public"); calls != 1 {
+		t.Errorf("event calls = %d, want the second feed served from the cache", calls)
+	}
+}
+
+// TestFeedUnknownUser Check that the feed of an unknown user is answered 404.
+func TestFeedUnknownUser(t *testing.T) {
+	f := newFakeGitHub(t)
+	g := newTestGStats(t, f, Config{})
+
+	rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=ghost&format=rss", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+}
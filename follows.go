@@ -0,0 +1,42 @@
+package githubstats
+
+import (
+	"context"
+
+	"github.com/google/go-github/github"
+)
+
+// listUsersFunc Signature shared by Users.ListFollowers and Users.ListFollowing.
+type listUsersFunc func(ctx context.Context, user string, opts *github.ListOptions) ([]*github.User, *github.Response, error)
+
+// fetchLogins Paginate a user list and collect the logins, stopping at the limit.
+/*
+ * @param ctx context.Context - The context
+ * @param list listUsersFunc - The listing call
+ * @param username string - The username
+ * @param limit int - The maximum number of logins
+ * @return []string, error - The logins, the error
+ */
+func fetchLogins(ctx context.Context, list listUsersFunc, username string, limit int) ([]string, error) {
+	logins := []string{}
+	listOpts := &github.ListOptions{PerPage: 100}
+	for len(logins) < limit {
+		users, resp, err := list(ctx, username, listOpts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, user := range users {
+			if len(logins) == limit {
+				break
+			}
+			logins = append(logins, user.GetLogin())
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		listOpts.Page = resp.NextPage
+	}
+	return logins, nil
+}
@@ -0,0 +1,40 @@
+package githubstats
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// TestFollowerLogins Check that the follower pages are read until MaxFollowLogins logins are collected.
+func TestFollowerLogins(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	f.handle("GET /users/octocat/followers", func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		page = max(page, 1)
+		setNextPage(w, r, page+1)
+		writeJSON(w, http.StatusOK, []map[string]string{
+			{"login": fmt.Sprintf("user-%d-a", page)},
+			{"login": fmt.Sprintf("user-%d-b", page)},
+		})
+	})
+	g := newTestGStats(t, f, Config{MaxFollowLogins: 5})
+
+	stats, err := g.GetGitHubStats("octocat", IncludeOptions{IncludeFollowerList: true})
+	if err != nil {
+		t.Fatalf("GetGitHubStats: %v", err)
+	}
+	want := []string{"user-1-a", "user-1-b", "user-2-a", "user-2-b", "user-3-a"}
+	if !reflect.DeepEqual(stats.FollowerLogins, want) {
+		t.Errorf("FollowerLogins = %v, want %v", stats.FollowerLogins, want)
+	}
+	if calls := f.count("GET /users/octocat/followers"); calls != 3 {
+		t.Errorf("follower pages = %d, want 3", calls)
+	}
+	if stats.FollowingLogins != nil {
+		t.Errorf("FollowingLogins = %v, want none", stats.FollowingLogins)
+	}
+}
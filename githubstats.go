@@ -1,7 +1,18 @@
 package githubstats
 
 import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"go/ast"
+	"io"
+	"log"
+	"math"
+	"net"
 	"sync"
 	"time"
 
@@ -9,7 +20,9 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/google/go-github/github"
 	"golang.org/x/oauth2"
@@ -22,58 +35,307 @@ type IncludeOptions struct {
 	IncludeFollowers   bool // Include followers
 	IncludeFollowing   bool // Include following
 	IncludeRepos       bool // Include repositories
-	IncludeFirstNRepos int  // Number of repositories to retrieve
+	IncludeFirstNRepos int  // Number of repositories to retrieve: 0 for the default 5, AllRepos for no limit, NoRepos for none
 	IncludeOrgs        bool // Include organizations
+
+	IncludeProfileReadme  bool // Include the profile README (from the username/username repository)
+	IncludeContributors   bool // Include the contributors of each repository
+	IncludeLanguages      bool // Include the languages of each repository
+	IncludeLatestRelease  bool // Include the latest release of each repository
+	IncludeCommitActivity bool // Include the weekly commits of the last year of each repository
+	HeavyMinStars         int  // Minimum stars for a repository to get its contributors, languages, release and commit activity
+	IncludeIssueBreakdown bool // Include the open and closed issue and pull request counts of each repository, up to MaxIssueBreakdownRepos
+	MinContributions      int  // Minimum contributions for a contributor to be kept, applied after MaxContributorsPerRepo (no filter if 0)
+
+	IncludeProfileLanguages      bool // Include the languages summed over every listed repository at or above HeavyMinStars, up to MaxLanguageRepos calls
+	IncludeExternalContributions bool // Include the repositories the user contributed to without owning them
+	IncludeStreak                bool // Include the current and longest contribution streaks
+	IncludeSponsors              bool // Include the GitHub Sponsors status
+	IncludeStarred               bool // Include the repositories the user starred, up to MaxStarredRepos
+	IncludeActivity              bool // Include the recent public pushes, stars and pull requests, up to MaxFeedItems, also served as a feed by format=rss or atom
+	IncludeOrgRoles              bool // Include the membership role of the user in each organization
+	IncludeOrgDetails            bool // Include the avatar, name and description of each organization, up to MaxOrgDetails profiles
+
+	IncludeFollowerList  bool // Include the logins of the followers, up to MaxFollowLogins
+	IncludeFollowingList bool // Include the logins of the followed users, up to MaxFollowLogins
+
+	PushedSince time.Time // Only list the repositories pushed to after this time (no filter if zero)
+
+	IncludePrivate bool   // Include the private repositories when the caller's token belongs to the user (see AllowPrivateWithServerToken), the result is never cached
+	TopBy          string // Order of the repositories: TopByStars, TopByScore or the GitHub order if empty
+
+	unknownPreset string // Preset query parameter that matched no preset, rejected by validateIncludeOptions
+}
+
+// defaultPresets Presets available with the preset query parameter, Config.Presets can add or replace them.
+var defaultPresets = map[string]IncludeOptions{
+	"minimal": {
+		IncludeStars:     true,
+		IncludeFollowers: true,
+	},
+	"social": {
+		IncludeFollowers: true,
+		IncludeFollowing: true,
+		IncludeOrgs:      true,
+	},
+	"full": {
+		IncludeStars:         true,
+		IncludeFollowers:     true,
+		IncludeFollowing:     true,
+		IncludeRepos:         true,
+		IncludeOrgs:          true,
+		IncludeProfileReadme: true,
+		IncludeLanguages:     true,
+		IncludeStreak:        true,
+
+		IncludeProfileLanguages: true,
+	},
 }
 
+// AccountType values, as reported by GitHub.
+const (
+	AccountTypeUser         = "User"
+	AccountTypeOrganization = "Organization"
+	AccountTypeBot          = "Bot"
+)
+
+// RateLimitMode values.
+const (
+	RateLimitModeReject = "reject" // Answer 429 when the rate limit is exceeded
+	RateLimitModeWait   = "wait"   // Wait for the rate limiter before answering 429
+)
+
+// AllRepos IncludeFirstNRepos value listing every repository.
+const AllRepos = -1
+
+// NoRepos IncludeFirstNRepos value returning no repository, the stars still being summed.
+const NoRepos = -2
+
+// defaultFirstNRepos Number of repositories returned when IncludeFirstNRepos is 0.
+const defaultFirstNRepos = 5
+
 type Config struct {
-	Path           string         // API path
-	Token          string         // GitHub token
-	IP             string         // IP address
-	Port           string         // Port
-	Scheme         string         // HTTP or HTTPS
-	CertFile       string         // Certificate file
-	KeyFile        string         // Key file
-	IncludeOptions IncludeOptions // Include options
-	CacheDuration  time.Duration  // Cache duration
-	RateLimit      int            // Rate limit
+	Path                  string                    // API path
+	ComparePath           string                    // Comparison API path
+	ReposPath             string                    // Repository stats API path, taking the "owner/name" pairs as a POST body
+	MaxReposPerRequest    int                       // Maximum number of repositories in a ReposPath request
+	OrgPath               string                    // Organization API path prefix, serving {org}/aggregate
+	OrgAwareFetch         bool                      // Fetch the organization accounts as such: all their public repositories, no organization list
+	HealthPath            string                    // Liveness path, never calling GitHub
+	ReadinessPath         string                    // Readiness path, answering 503 when GitHub is unreachable
+	ReadinessTimeout      time.Duration             // Timeout of the readiness GitHub probe
+	Token                 string                    // GitHub token
+	Tokens                []string                  // Additional GitHub tokens used in rotation
+	TokenSource           oauth2.TokenSource        // Source of expiring tokens, e.g. GitHub App installation tokens, used in rotation with the static ones
+	IP                    string                    // IP address
+	Port                  string                    // Port
+	Scheme                string                    // "http" (default) or "https", anything else is rejected by Connect
+	CertFile              string                    // Certificate file
+	KeyFile               string                    // Key file
+	IncludeOptions        IncludeOptions            // Default include options, used when a query parameter is missing
+	ForceIncludeOptions   map[string]string         // Query parameters forced on every request, e.g. {"include_contributors": "false"}
+	Presets               map[string]IncludeOptions // Option sets selected with the preset query parameter, added to "minimal", "social" and "full"
+	CacheDuration         time.Duration             // Cache duration
+	DisableCache          bool                      // Fetch every request from GitHub, WarmOnStart is then ignored
+	SectionCacheDurations map[string]time.Duration  // Shorter durations of the "profile", "stars" (with the stars of each repository), "repositories" or "organizations" sections, refreshed alone on a cache hit
+	RateLimit             int                       // Rate limit
+	RateBurst             int                       // Burst capacity above the steady rate limit (fixed window if 0)
+	EndpointRateLimits    map[string]int            // Requests per minute of a registered path, e.g. {"/compare": 2}, replacing RateLimit for it, RateBurst being capped to it
+	RateLimitMode         string                    // "reject" (default) answers 429 right away, "wait" queues the request up to MaxRateLimitWait
+	EmptyIncludeMode      string                    // Requests selecting no section: "fetch" (default) the user anyway, "reject" with a 400 or answer the "username" alone
+	MaxRateLimitWait      time.Duration             // Maximum time a request waits for the rate limiter in the "wait" mode
+	MaxOrgPages           int                       // Maximum number of organization pages to retrieve
+	MaxOrgDetails         int                       // Maximum number of organization profiles fetched by include_org_details
+	MaxOrgMembers         int                       // Maximum number of members summed by the organization aggregate
+	MaxOrgRepos           int                       // Maximum number of repositories whose contributors the organization aggregate counts
+	MaxContributorPages   int                       // Maximum number of contributor pages of 100 counted per organization repository
+	MaxTopContributors    int                       // Number of contributors in the organization top contributors ranking
+
+	BreakerThreshold      int           // Consecutive GitHub failures before the circuit breaker opens
+	BreakerCooldown       time.Duration // Time the circuit breaker stays open before a probe
+	MaxRetryAfterJitter   time.Duration // Maximum random time added to the Retry-After of the 429 and 503 responses, rounded to seconds (none if 0)
+	ErrorRateWindow       time.Duration // Period of the GitHub error ratio reported by the health endpoint (5 minutes if 0)
+	DegradedErrorRate     float64       // GitHub error ratio (0 to 1) over which the health endpoint reports "degraded" (never if 0)
+	ErrorTTLMultiplier    float64       // Multiplier of the cache durations of the stats fetched while a GitHub call failed within ErrorRateWindow, at least 1 (disabled if 0)
+	DegradedStatusCode    int           // HTTP status of a degraded health response (200 if 0, e.g. 503 to fail the checks)
+	HandlerTimeout        time.Duration // Maximum total time to serve a request
+	ResponseBudget        time.Duration // Time after which no new optional section is fetched, the response is marked partial (disabled if 0)
+	DeadlineMargin        time.Duration // Time kept before the request deadline, e.g. HandlerTimeout, to answer with the sections fetched so far
+	GitHubTimeout         time.Duration // Default timeout of the GitHub calls of each section, and of the details of each repository (none if 0)
+	UserTimeout           time.Duration // Timeout of the user call (GitHubTimeout if 0)
+	ReposTimeout          time.Duration // Timeout of the repository listing call (GitHubTimeout if 0)
+	OrgsTimeout           time.Duration // Timeout of each organization listing call (GitHubTimeout if 0)
+	MaxRetriesPerRequest  int           // Maximum number of GitHub call retries shared by a request (disabled if 0)
+	MaxAPICallsPerRequest int           // Maximum number of GitHub calls of a user's stats, retries included, the response is then partial (unlimited if 0)
+	ConditionalRequests   bool          // Revalidate the repository calls with If-Modified-Since to save quota
+	MaxIdleConnsPerHost   int           // Idle connections kept open to GitHub per token (2 if 0)
+	MaxConnsPerHost       int           // Connections open at once to GitHub per token, the calls beyond wait (unlimited if 0)
+	APIVersion            string        // GitHub REST API version sent as X-GitHub-Api-Version (DefaultAPIVersion if empty)
+	AccessLog             io.Writer     // Access log output in Combined Log Format (disabled if nil)
+	RedactUsernamesInLogs bool          // Replace the usernames with a stable hash in the logs
+	RedactionKey          string        // Secret keying the username hashes, the same on the instances whose logs are correlated (random per process if empty)
+	ReusePort             bool          // Set SO_REUSEPORT so several processes can share the port
+	TrustedProxies        []string      // IPs or CIDRs of the proxies allowed to set X-Forwarded-For and X-Real-IP
+
+	ServeStaleOnError     bool          // Serve an expired cache entry when GitHub is unreachable
+	MaxStaleDuration      time.Duration // Maximum time past its expiration an entry can be served stale (unlimited if 0)
+	NegativeCacheDuration time.Duration // Time a "user not found" result is cached (disabled if 0)
+	PartialCacheDuration  time.Duration // Time the stats cut short by MaxAPICallsPerRequest are cached, as a new fetch would be cut short too (1 minute if 0, at most CacheDuration)
+	SuspendedStatusCode   int           // HTTP status of a suspended or deleted account (410 if 0, e.g. 404 to handle it like a missing user)
+	RefreshAheadWindow    time.Duration // A hit expiring within this window is served and refreshed in the background (disabled if 0)
+	CompressCache         bool          // Store cache entries gzipped to reduce memory
+	MaxCacheEntrySize     int           // Maximum size in bytes of a cached value (its JSON, gzipped with CompressCache), larger ones are fetched each time (unlimited if 0), set with SetMaxEntrySize on a shared Cache
+	Cache                 *Cache        // Cache shared with other instances, e.g. NewCache() (a private one is created if nil)
+	CacheKeyPrefix        string        // Prefix of the cache keys, to share a cache between deployments
+	MaxBatchSize          int           // Maximum number of usernames in a batch request
+	BatchConcurrency      int           // Maximum number of users fetched concurrently in a batch request
+
+	MaxContributedRepos    int          // Maximum number of external repositories the user contributed to
+	MaxStarredRepos        int          // Maximum number of starred repositories
+	MaxIssueBreakdownRepos int          // Maximum number of repositories getting an issue breakdown, each one costs a GraphQL query
+	TimeFormat             string       // Time fields format: "rfc3339" (default), "unix" or "unixms"
+	OmitZeroFields         bool         // Leave the zero and disabled fields out of the responses
+	EnvelopeResponses      bool         // Wrap the responses in {"schema_version": SchemaVersion, "data": ...}
+	MaxTopLanguages        int          // Number of languages in the top languages ranking
+	MaxLanguageRepos       int          // Maximum number of listed repositories whose languages are fetched for profile_languages, on top of the returned ones
+	ScoreWeights           ScoreWeights // Weights of the top_by=score ranking (1 per star, 2 per fork, 10 for recency if empty)
+	StrictParams           bool         // Answer 400 to the requests with unknown query parameters, e.g. a misspelled include_star
+	MaxContributorsPerRepo int          // Maximum number of contributors per repository, the top ones are kept (unlimited if 0)
+	MaxFeedItems           int          // Maximum number of entries in the activity feed
+	MaxFollowLogins        int          // Maximum number of logins in the follower and following lists
+
+	CommitActivityRetries    int           // Rounds of retries of the repositories whose commit activity GitHub is still computing, the response is then partial (3 if 0, none if negative)
+	CommitActivityRetryDelay time.Duration // Time before each round of commit activity retries (1 second if 0)
+
+	CustomComputers []StatComputer // Custom stat computers, a failing one adds a warning instead of failing the request
+
+	AdminToken string // Bearer token for the admin endpoints (disabled if empty)
+	AdminPath  string // Admin endpoints prefix
+
+	MaxRequestBodySize int64 // Maximum size of a request body in bytes
+
+	WarmOnStart []string // Users fetched and cached in the background when the server starts, with the default include options
+
+	StaticResponses map[string]GitHubStats // Canned stats returned as is for these usernames (case-insensitive) without calling GitHub, e.g. for demos
+
+	ShutdownTimeout time.Duration // Time Shutdown waits for the in-flight requests before closing the connections
+
+	AllowUserTokens bool // Let callers send their own GitHub token as a bearer token, their results are never cached
+
+	AllowPrivateWithServerToken bool // Honor include_private without a caller's token, exposing the private repositories of the server token owner to every caller
 }
 
 type CacheEntry struct {
 	Stats      GitHubStats
 	Expiration time.Time
+	NotFound   bool                 // Negative entry: the user does not exist
+	TTL        time.Duration        // Time left before the expiration when the snapshot was taken, only set by Snapshot
+	Sections   map[string]time.Time `json:",omitempty"` // Expiration of the sections refreshed before the entry, see SectionCacheDurations
+	compressed []byte               // gzipped JSON of Stats when the cache is compressed
 }
 
+// ErrUserNotFound is returned when the GitHub user does not exist.
+var ErrUserNotFound = errors.New("user not found")
+
+// ErrRateLimited is returned when GitHub rejects the calls because the token quota is exhausted.
+// The *github.RateLimitError or *github.AbuseRateLimitError is still available with errors.As.
+var ErrRateLimited = errors.New("github rate limit exceeded")
+
+// ErrGitHubUnavailable is returned when GitHub can't be reached or fails (network error, timeout, 5xx, open circuit breaker).
+var ErrGitHubUnavailable = errors.New("github is unavailable")
+
+// ErrRequestLimitExceeded is returned by Stats when the rate limiter rejects the call.
+var ErrRequestLimitExceeded = errors.New("request limit exceeded")
+
 type Organizations struct {
 	Organizations []string `json:"organizations"`
 }
 
 type GitHubStats struct {
-	Username      string      `json:"username"`
-	Followers     int         `json:"followers"`
-	Following     int         `json:"following"`
-	TotalStars    int         `json:"total_stars"`
-	Repositories  []RepoStats `json:"repositories"`
-	Organizations []string    `json:"organizations"`
+	Username          string      `json:"username"`
+	AccountType       string      `json:"account_type"` // AccountTypeUser, AccountTypeOrganization or AccountTypeBot
+	Followers         int         `json:"followers"`
+	Following         int         `json:"following"`
+	TotalStars        int         `json:"total_stars"`
+	TotalRepositories int         `json:"total_repositories"`
+	Repositories      []RepoStats `json:"repositories"`
+	Organizations     []string    `json:"organizations"`
+	ProfileReadme     string      `json:"profile_readme"`
+
+	ContributedRepositories []RepoStats    `json:"contributed_repositories"`
+	StarredRepositories     []RepoStats    `json:"starred_repositories"`
+	Activity                []ActivityItem `json:"activity"`
+	CurrentStreak           int            `json:"current_streak"`
+	LongestStreak           int            `json:"longest_streak"`
+	TopLanguages            []LanguageStat `json:"top_languages"`
+	ProfileLanguages        []LanguageStat `json:"profile_languages"` // With IncludeProfileLanguages, every language of all the listed repositories at or above HeavyMinStars, not only the returned ones, the percentages summing to 100
+	OrganizationDetails     []OrgInfo      `json:"organization_details"`
+	FollowerLogins          []string       `json:"follower_logins"`
+	FollowingLogins         []string       `json:"following_logins"`
+	Sponsors                *SponsorsInfo  `json:"sponsors"`
+
+	Computed      *float64               `json:"computed,omitempty"`       // Value of the expr query parameter
+	Custom        map[string]interface{} `json:"custom,omitempty"`         // Values set by the custom stat computers
+	Warnings      []string               `json:"warnings,omitempty"`       // Sections that could not be computed
+	Partial       bool                   `json:"partial,omitempty"`        // Some sections were skipped to fit in ResponseBudget or MaxAPICallsPerRequest
+	BudgetLimited bool                   `json:"budget_limited,omitempty"` // Some sections were skipped or cut short by MaxAPICallsPerRequest
+	Deltas        *StatsDeltas           `json:"deltas,omitempty"`         // Changes since the previous cached value
+
+	omitZero bool // Leave out the zero fields when serializing, see OmitZeroFields
 }
 
+// StatComputer Compute bespoke stats after the built-in fetch, e.g. by setting stats.Custom values.
+type StatComputer func(ctx context.Context, client *github.Client, username string, stats *GitHubStats) error
+
 type RepoStats struct {
 	Name         string         `json:"name"`
 	Stars        int            `json:"stars"`
 	Forks        int            `json:"forks"`
 	OpenIssues   int            `json:"open_issues"`
 	Contributors map[string]int `json:"contributors"`
+	Languages    map[string]int `json:"languages"`
+	CreatedAt    JSONTime       `json:"created_at"`
+	UpdatedAt    JSONTime       `json:"updated_at"`
+	PushedAt     JSONTime       `json:"pushed_at"`
+
+	DefaultBranch string       `json:"default_branch"`
+	Private       bool         `json:"private"`
+	LatestRelease *ReleaseInfo `json:"latest_release"` // Set with include_latest_release, null if the repository has no release
+	WeeklyCommits []int        `json:"weekly_commits"` // Set with include_commit_activity, the last 52 weeks oldest first, null while GitHub computes them
+
+	IssueBreakdown *IssueBreakdown `json:"issue_breakdown"` // Set with include_issue_breakdown for the first MaxIssueBreakdownRepos repositories
 }
 
 type GStats struct {
-	client      *github.Client
-	cache       *Cache
-	rateLimiter *RateLimiter
+	config           Config
+	client           *github.Client
+	clients          *clientPool
+	transport        http.RoundTripper // Base transport shared by every GitHub client, the per-request ones included
+	cache            *Cache
+	rateLimiter      *RateLimiter
+	endpointLimiters map[string]*RateLimiter // Limiters of the paths with their own rate limit
+	breaker          *CircuitBreaker
+	errorRate        *ErrorRate
+	accessLog        *accessLogger
+	mux              *http.ServeMux // Routes of the instance
+
+	serverMu sync.Mutex
+	server   *http.Server
+
+	refreshMu  sync.Mutex
+	refreshing map[string]bool // Cache keys with a refresh-ahead in progress
+
+	trustedProxies []*net.IPNet
+	redactionKey   []byte // Key of the username hashes, nil without RedactUsernamesInLogs
+
+	staticResponses map[string]GitHubStats // StaticResponses by lowercase username
 }
 
 type Cache struct {
-	mu    sync.RWMutex
-	store map[string]CacheEntry
+	mu           sync.RWMutex
+	store        map[string]CacheEntry
+	compress     bool
+	maxEntrySize int // Maximum size of a value in bytes, larger ones are not cached (unlimited if 0)
 }
 
 type RateLimiter struct {
@@ -82,6 +344,9 @@ type RateLimiter struct {
 	lastRequest time.Time
 	limit       int
 	interval    time.Duration
+	burst       int       // Token bucket capacity (fixed window if 0)
+	tokens      float64   // Tokens left in the bucket
+	lastRefill  time.Time // Last time the bucket was refilled
 }
 
 // RateLimiter Fonctions
@@ -99,6 +364,82 @@ func NewRateLimiter(limit int, interval time.Duration) *RateLimiter {
 	}
 }
 
+// NewRateLimiterWithBurst Create a new token bucket rate limiter, refilled at limit per interval and
+// allowing bursts up to burst requests.
+/*
+ * @param limit int - The limit
+ * @param interval time.Duration - The interval
+ * @param burst int - The burst capacity
+ * @return *RateLimiter - The rate limiter
+ */
+func NewRateLimiterWithBurst(limit int, interval time.Duration, burst int) *RateLimiter {
+	return &RateLimiter{
+		limit:      limit,
+		interval:   interval,
+		burst:      burst,
+		tokens:     float64(burst),
+		lastRefill: time.Now(),
+	}
+}
+
+// newMinuteRateLimiter Create a rate limiter of limit requests per minute, with a token bucket if burst is set.
+/*
+ * @param limit int - The number of requests per minute
+ * @param burst int - The burst capacity (fixed window if 0)
+ * @return *RateLimiter - The rate limiter
+ */
+func newMinuteRateLimiter(limit int, burst int) *RateLimiter {
+	if burst > 0 {
+		return NewRateLimiterWithBurst(limit, 1*time.Minute, burst)
+	}
+	return NewRateLimiter(limit, 1*time.Minute)
+}
+
+// limiterFor Get the rate limiter of a path, the global one unless it has its own in EndpointRateLimits.
+/*
+ * @param path string - The registered path
+ * @return *RateLimiter - The rate limiter
+ */
+func (g *GStats) limiterFor(path string) *RateLimiter {
+	if limiter, ok := g.endpointLimiters[path]; ok {
+		return limiter
+	}
+	return g.rateLimiter
+}
+
+// admit Check the rate limiter, waiting for it in the "wait" RateLimitMode.
+/*
+ * @param ctx context.Context - The request context
+ * @param limiter *RateLimiter - The rate limiter
+ * @return bool - Whether the request is allowed
+ */
+func (g *GStats) admit(ctx context.Context, limiter *RateLimiter) bool {
+	if g.config.RateLimitMode == RateLimitModeWait {
+		return limiter.Wait(ctx, g.config.MaxRateLimitWait)
+	}
+	return limiter.Allow()
+}
+
+// limitEndpoint Wrap a handler so it is rate limited when its path has its own limit in EndpointRateLimits.
+/*
+ * @param path string - The registered path
+ * @param next http.HandlerFunc - The handler
+ * @return http.HandlerFunc - The handler
+ */
+func (g *GStats) limitEndpoint(path string, next http.HandlerFunc) http.HandlerFunc {
+	limiter, ok := g.endpointLimiters[path]
+	if !ok {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !g.admit(r.Context(), limiter) {
+			g.writeRateLimited(w, r, limiter)
+			return
+		}
+		next(w, r)
+	}
+}
+
 // Allow Allow a request.
 /*
  * @return bool - The result
@@ -107,6 +448,10 @@ func (rl *RateLimiter) Allow() bool {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
+	if rl.burst > 0 {
+		return rl.allowToken()
+	}
+
 	if time.Since(rl.lastRequest) > rl.interval {
 		rl.requests = 0
 	}
@@ -119,6 +464,89 @@ func (rl *RateLimiter) Allow() bool {
 	return false
 }
 
+// Reset Clear the current window, or refill the bucket, so the requests are allowed again right away.
+/*
+ * @return void
+ */
+func (rl *RateLimiter) Reset() {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.requests = 0
+	rl.tokens = float64(rl.burst)
+	rl.lastRefill = time.Now()
+}
+
+// RetryAfter Get the time before a request is allowed again.
+/*
+ * @return time.Duration - The duration, at least a second
+ */
+func (rl *RateLimiter) RetryAfter() time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	wait := rl.interval
+	if rl.burst > 0 && rl.limit > 0 {
+		// Time to refill the missing fraction of a token
+		rate := float64(rl.limit) / float64(rl.interval)
+		wait = time.Duration((1 - rl.tokens) / rate)
+	} else if rl.burst == 0 {
+		wait = rl.interval - time.Since(rl.lastRequest)
+	}
+	if wait < time.Second {
+		return time.Second
+	}
+	return wait
+}
+
+// rateLimitPollInterval Time between two admission attempts while waiting for the rate limiter.
+const rateLimitPollInterval = 50 * time.Millisecond
+
+// Wait Wait until a request is allowed, giving up after maxWait or when the context is done.
+/*
+ * @param ctx context.Context - The context
+ * @param maxWait time.Duration - The maximum time to wait
+ * @return bool - Whether the request is allowed
+ */
+func (rl *RateLimiter) Wait(ctx context.Context, maxWait time.Duration) bool {
+	if rl.Allow() {
+		return true
+	}
+
+	deadline := time.NewTimer(maxWait)
+	defer deadline.Stop()
+	ticker := time.NewTicker(rateLimitPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-deadline.C:
+			return false
+		case <-ticker.C:
+			if rl.Allow() {
+				return true
+			}
+		}
+	}
+}
+
+// allowToken Take a token from the bucket, refilling it first. The caller holds the lock.
+/*
+ * @return bool - The result
+ */
+func (rl *RateLimiter) allowToken() bool {
+	now := time.Now()
+	rate := float64(rl.limit) / float64(rl.interval)
+	rl.tokens = math.Min(float64(rl.burst), rl.tokens+float64(now.Sub(rl.lastRefill))*rate)
+	rl.lastRefill = now
+
+	if rl.tokens >= 1 {
+		rl.tokens--
+		return true
+	}
+	return false
+}
+
 // Cache Fonctions
 
 // NewCache Create a new cache.
@@ -131,176 +559,1707 @@ func NewCache() *Cache {
 	}
 }
 
+// NewCompressedCache Create a new cache storing its values gzipped, trading CPU for memory.
+/*
+ * @return *Cache - The cache
+ */
+func NewCompressedCache() *Cache {
+	return &Cache{
+		store:    make(map[string]CacheEntry),
+		compress: true,
+	}
+}
+
 // Get Get the cache entry.
 /*
  * @param key string - The key
  * @return GitHubStats, bool - The stats, found
  */
 func (c *Cache) Get(key string) (GitHubStats, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	entry, found := c.store[key]
-	if !found || time.Now().After(entry.Expiration) {
+	entry, found := c.GetEntry(key)
+	if !found || entry.NotFound || time.Now().After(entry.Expiration) {
 		return GitHubStats{}, false
 	}
 	return entry.Stats, true
 }
 
-// Set Set the cache entry.
+// Expiration Get the expiration of an entry.
+/*
+ * @param key string - The key
+ * @return time.Time, bool - The expiration, found
+ */
+func (c *Cache) Expiration(key string) (time.Time, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, found := c.store[key]
+	return entry.Expiration, found
+}
+
+// IsNotFound Check if the key has a negative entry that has not expired.
+/*
+ * @param key string - The key
+ * @return bool - The result
+ */
+func (c *Cache) IsNotFound(key string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, found := c.store[key]
+	return found && entry.NotFound && !time.Now().After(entry.Expiration)
+}
+
+// SetNotFound Set a negative entry for a user that does not exist.
 /*
  * @param key string - The key
- * @param stats GitHubStats - The stats
  * @param duration time.Duration - The duration
  * @return void
  */
-func (c *Cache) Set(key string, stats GitHubStats, duration time.Duration) {
+func (c *Cache) SetNotFound(key string, duration time.Duration) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.store[key] = CacheEntry{
-		Stats:      stats,
 		Expiration: time.Now().Add(duration),
+		NotFound:   true,
 	}
 }
 
-// parseIncludeOptions Parse the include options.
+// GetEntry Get the cache entry, even if it has expired.
 /*
- * @param query url.Values - The query
- * @return IncludeOptions - The options
+ * @param key string - The key
+ * @return CacheEntry, bool - The entry, found
  */
-func (g *GStats) parseIncludeOptions(query url.Values) IncludeOptions {
-	opts := IncludeOptions{
-		IncludeStars:       query.Get("include_stars") == "true",
-		IncludeFollowers:   query.Get("include_followers") == "true",
-		IncludeFollowing:   query.Get("include_following") == "true",
-		IncludeRepos:       query.Get("include_repos") == "true",
-		IncludeOrgs:        query.Get("include_orgs") == "true",
-		IncludeFirstNRepos: 5, // Valeur par défaut
-	}
+func (c *Cache) GetEntry(key string) (CacheEntry, bool) {
+	c.mu.RLock()
+	entry, found := c.store[key]
+	c.mu.RUnlock()
 
-	if firstN := query.Get("include_first_n_repos"); firstN != "" {
-		if n, err := strconv.Atoi(firstN); err == nil {
-			opts.IncludeFirstNRepos = n
+	if found && entry.compressed != nil {
+		stats, err := decompressStats(entry.compressed)
+		if err != nil {
+			return CacheEntry{}, false
 		}
+		entry.Stats = stats
+		entry.compressed = nil
 	}
-
-	return opts
+	return entry, found
 }
 
-// githubStatsHandler Handle the requests to get the GitHub stats.
+// Keys Get the keys of the entries that have not expired, sorted.
 /*
- * @param w http.ResponseWriter - The response writer
- * @param r *http.Request - The request
- * @param config Config - The configuration
- * @return void
+ * @return []string - The keys
  */
-func (g *GStats) githubStatsHandler(w http.ResponseWriter, r *http.Request, config Config) {
-	query := r.URL.Query()
-	username := query.Get("username")
-
-	if username == "" {
-		http.Error(w, "Le nom d'utilisateur est requis", http.StatusBadRequest)
-		return
+func (c *Cache) Keys() []string {
+	entries := c.Entries()
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
 	}
+	sort.Strings(keys)
+	return keys
+}
 
-	// Check the request limit
-	if !g.rateLimiter.Allow() {
-		http.Error(w, "Request limit exceeded", http.StatusTooManyRequests)
-		return
+// Entries Get the expiration of the entries that have not expired.
+/*
+ * @return map[string]time.Time - The expiration by key
+ */
+func (c *Cache) Entries() map[string]time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	now := time.Now()
+	entries := make(map[string]time.Time, len(c.store))
+	for key, entry := range c.store {
+		if !now.After(entry.Expiration) {
+			entries[key] = entry.Expiration
+		}
 	}
+	return entries
+}
 
-	// Check the cache
-	if cachedStats, found := g.cache.Get(username); found {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(cachedStats)
-		return
+// Snapshot Get a copy of every entry, expired ones included, with the stats decompressed.
+/*
+ * @return map[string]CacheEntry - The entries by key
+ */
+func (c *Cache) Snapshot() map[string]CacheEntry {
+	c.mu.RLock()
+	keys := make([]string, 0, len(c.store))
+	for key := range c.store {
+		keys = append(keys, key)
 	}
+	c.mu.RUnlock()
 
-	// Get the include options
-	opts := g.parseIncludeOptions(query)
-
-	stats, err := g.GetGitHubStats(username, opts)
-	if err != nil {
-		http.Error(w, "Erreur lors de la récupération des données", http.StatusInternalServerError)
-		return
+	now := time.Now()
+	snapshot := make(map[string]CacheEntry, len(keys))
+	for _, key := range keys {
+		// GetEntry decompresses, and skips entries removed in the meantime
+		if entry, found := c.GetEntry(key); found {
+			entry.TTL = entry.Expiration.Sub(now)
+			snapshot[key] = entry
+		}
 	}
-
-	// Cache the stats
-	g.cache.Set(username, stats, config.CacheDuration)
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(stats)
+	return snapshot
 }
 
-// GetGitHubStats Get the GitHub stats for a given user according to the specified options.
+// Restore Add the entries of a snapshot, replacing the existing ones with the same key.
 /*
- * @param username string - The username
- * @param opts IncludeOptions - The options
- * @return GitHubStats, error - The stats, the error
+ * The expiration is recomputed from the TTL, so the entries keep the time they had left when the snapshot was taken.
+ *
+ * @param snapshot map[string]CacheEntry - The entries by key
+ * @return void
  */
-func (g *GStats) GetGitHubStats(username string, opts IncludeOptions) (GitHubStats, error) {
-	ctx := context.Background()
-
-	user, _, err := g.client.Users.Get(ctx, username)
-	if err != nil {
-		return GitHubStats{}, err
+func (c *Cache) Restore(snapshot map[string]CacheEntry) {
+	now := time.Now()
+	entries := make(map[string]CacheEntry, len(snapshot))
+	for key, entry := range snapshot {
+		if entry.TTL != 0 {
+			entry.Expiration = now.Add(entry.TTL)
+		}
+		entry.TTL = 0
+		entry.compressed = nil
+		if c.compress && !entry.NotFound {
+			if data, err := compressStats(entry.Stats); err == nil {
+				entry.Stats = GitHubStats{}
+				entry.compressed = data
+			}
+		}
+		entries[key] = entry
 	}
 
-	repos, _, err := g.client.Repositories.List(ctx, username, nil)
-	if err != nil {
-		return GitHubStats{}, err
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, entry := range entries {
+		c.store[key] = entry
 	}
+}
 
-	stats := GitHubStats{
-		Username: username,
+// StaleFor Get how long ago the entry expired.
+/*
+ * @return time.Duration - The duration (0 if the entry has not expired)
+ */
+func (e CacheEntry) StaleFor() time.Duration {
+	if stale := time.Since(e.Expiration); stale > 0 {
+		return stale
 	}
+	return 0
+}
 
-	if opts.IncludeFollowers {
-		stats.Followers = *user.Followers
-	}
-	if opts.IncludeFollowing {
-		stats.Following = *user.Following
+// Set Set the cache entry.
+/*
+ * @param key string - The key
+ * @param stats GitHubStats - The stats
+ * @param duration time.Duration - The duration
+ * @return void
+ */
+func (c *Cache) Set(key string, stats GitHubStats, duration time.Duration) {
+	c.SetSections(key, stats, duration, nil)
+}
+
+// SetSections Set the cache entry, with the expiration of the sections refreshed on their own.
+/*
+ * @param key string - The key
+ * @param stats GitHubStats - The stats
+ * @param duration time.Duration - The duration of the entry
+ * @param sections map[string]time.Time - The expiration by section (nil if none)
+ * @return void
+ */
+func (c *Cache) SetSections(key string, stats GitHubStats, duration time.Duration, sections map[string]time.Time) {
+	entry := CacheEntry{
+		Stats:      stats,
+		Expiration: time.Now().Add(duration),
+		Sections:   sections,
 	}
-	if opts.IncludeStars || opts.IncludeRepos {
-		for i, repo := range repos {
-			if opts.IncludeStars {
-				stats.TotalStars += *repo.StargazersCount
-			}
-			if opts.IncludeRepos {
-				if opts.IncludeFirstNRepos > 0 && i >= opts.IncludeFirstNRepos {
-					break
-				}
-				stats.Repositories = append(stats.Repositories, RepoStats{
-					Name:  *repo.Name,
-					Stars: *repo.StargazersCount,
-					Forks: *repo.ForksCount,
-				})
-			}
+	if c.compress {
+		// Keep the value uncompressed if it can't be serialized
+		if data, err := compressStats(stats); err == nil {
+			entry.Stats = GitHubStats{}
+			entry.compressed = data
 		}
 	}
 
-	if opts.IncludeOrgs {
-		orgs, _, err := g.client.Organizations.List(ctx, username, nil)
-		if err != nil {
-			return GitHubStats{}, err
-		}
+	maxSize := c.MaxEntrySize()
+	tooLarge := maxSize > 0 && entrySize(entry) > maxSize
 
-		for _, org := range orgs {
-			stats.Organizations = append(stats.Organizations, *org.Login)
-		}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if tooLarge {
+		// Dropping the previous value too, it would be served instead of the fresh one
+		delete(c.store, key)
+		return
 	}
+	c.store[key] = entry
+}
 
-	return stats, nil
+// SetMaxEntrySize Set the maximum size of a cached value in bytes, the larger ones are not cached.
+/*
+ * The size is the one of the JSON, gzipped for a compressed cache.
+ *
+ * @param size int - The size (unlimited if 0)
+ * @return void
+ */
+func (c *Cache) SetMaxEntrySize(size int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxEntrySize = size
 }
 
-// Connect initialise le client GitHub avec le token et configure le serveur.
+// MaxEntrySize Get the maximum size of a cached value in bytes.
 /*
- * @param config Config - The configuration
- * @return error? - The error
+ * @return int - The size (unlimited if 0)
  */
-func (g *GStats) Connect(config Config) error {
-	// Check if the token is defined
-	if config.Token == "" {
-		return fmt.Errorf("le token GitHub doit être défini")
+func (c *Cache) MaxEntrySize() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.maxEntrySize
+}
+
+// entrySize Get the size of the value of an entry.
+/*
+ * @param entry CacheEntry - The entry
+ * @return int - The size in bytes, unknown values being too large
+ */
+func entrySize(entry CacheEntry) int {
+	if entry.compressed != nil {
+		return len(entry.compressed)
+	}
+	data, err := json.Marshal(entry.Stats)
+	if err != nil {
+		return math.MaxInt
+	}
+	return len(data)
+}
+
+// compressStats Serialize the stats to gzipped JSON.
+/*
+ * @param stats GitHubStats - The stats
+ * @return []byte, error - The compressed data, the error
+ */
+func compressStats(stats GitHubStats) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(stats); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decompressStats Deserialize the stats from gzipped JSON.
+/*
+ * @param data []byte - The compressed data
+ * @return GitHubStats, error - The stats, the error
+ */
+func decompressStats(data []byte) (GitHubStats, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return GitHubStats{}, err
+	}
+	defer zr.Close()
+
+	var stats GitHubStats
+	err = json.NewDecoder(zr).Decode(&stats)
+	return stats, err
+}
+
+// needsRepos Check if the options require listing the repositories.
+/*
+ * Contributors and languages are only fetched for listed repositories, so they need IncludeRepos.
+ *
+ * @return bool - The result
+ */
+func (opts IncludeOptions) needsRepos() bool {
+	return opts.IncludeStars || opts.IncludeRepos
+}
+
+// firstNRepos Get the number of repositories to return.
+/*
+ * @return int - The number, AllRepos for no limit
+ */
+func (opts IncludeOptions) firstNRepos() int {
+	switch opts.IncludeFirstNRepos {
+	case 0:
+		return defaultFirstNRepos
+	case NoRepos:
+		return 0
+	default:
+		return opts.IncludeFirstNRepos
+	}
+}
+
+// needsRepoDetails Check if the options require a call per listed repository.
+/*
+ * @return bool - The result
+ */
+func (opts IncludeOptions) needsRepoDetails() bool {
+	return opts.IncludeContributors || opts.IncludeLanguages || opts.IncludeLatestRelease || opts.IncludeCommitActivity
+}
+
+// parseIncludeOptions Parse the include options.
+/*
+ * Config.IncludeOptions gives the defaults of the missing parameters, and ForceIncludeOptions overrides the query.
+ *
+ * @param query url.Values - The query
+ * @return IncludeOptions, error - The options, the error if a parameter can't be parsed
+ */
+func (g *GStats) parseIncludeOptions(query url.Values) (IncludeOptions, error) {
+	values := make(url.Values, len(query)+len(g.config.ForceIncludeOptions))
+	for name, value := range query {
+		values[name] = value
+	}
+	for name, value := range g.config.ForceIncludeOptions {
+		values.Set(name, value)
+	}
+
+	// A preset replaces the defaults, the explicit parameters still win
+	defaults := g.config.IncludeOptions
+	var unknownPreset string
+	if name := values.Get("preset"); name != "" {
+		if preset, ok := g.lookupPreset(name); ok {
+			defaults = preset
+		} else {
+			unknownPreset = name
+		}
+	}
+	// The query counts from 0 for none and any negative number for all
+	firstN := defaults.IncludeFirstNRepos
+	if firstN == 0 {
+		firstN = defaultFirstNRepos // Valeur par défaut
+	}
+	if values.Has("include_first_n_repos") {
+		switch n := intParam(values, "include_first_n_repos", firstN); {
+		case n == 0:
+			firstN = NoRepos
+		case n < 0:
+			firstN = AllRepos
+		default:
+			firstN = n
+		}
+	}
+	pushedSince, err := timeParam(values, "pushed_since", defaults.PushedSince)
+	if err != nil {
+		return IncludeOptions{}, fmt.Errorf("pushed_since: %w", err)
+	}
+
+	return IncludeOptions{
+		IncludeStars:       boolParam(values, "include_stars", defaults.IncludeStars),
+		IncludeFollowers:   boolParam(values, "include_followers", defaults.IncludeFollowers),
+		IncludeFollowing:   boolParam(values, "include_following", defaults.IncludeFollowing),
+		IncludeRepos:       boolParam(values, "include_repos", defaults.IncludeRepos),
+		IncludeOrgs:        boolParam(values, "include_orgs", defaults.IncludeOrgs),
+		IncludeFirstNRepos: firstN,
+
+		IncludeProfileReadme:  boolParam(values, "include_readme", defaults.IncludeProfileReadme),
+		IncludeContributors:   boolParam(values, "include_contributors", defaults.IncludeContributors),
+		IncludeLanguages:      boolParam(values, "include_languages", defaults.IncludeLanguages),
+		IncludeLatestRelease:  boolParam(values, "include_latest_release", defaults.IncludeLatestRelease),
+		IncludeCommitActivity: boolParam(values, "include_commit_activity", defaults.IncludeCommitActivity),
+		HeavyMinStars:         intParam(values, "heavy_min_stars", defaults.HeavyMinStars),
+		IncludeIssueBreakdown: boolParam(values, "include_issue_breakdown", defaults.IncludeIssueBreakdown),
+		MinContributions:      intParam(values, "min_contributions", defaults.MinContributions),
+
+		IncludeProfileLanguages:      boolParam(values, "include_profile_languages", defaults.IncludeProfileLanguages),
+		IncludeExternalContributions: boolParam(values, "include_external_contributions", defaults.IncludeExternalContributions),
+		IncludeStreak:                boolParam(values, "include_streak", defaults.IncludeStreak),
+		IncludeSponsors:              boolParam(values, "include_sponsors", defaults.IncludeSponsors),
+		IncludeStarred:               boolParam(values, "include_starred", defaults.IncludeStarred),
+		IncludeActivity:              boolParam(values, "include_activity", defaults.IncludeActivity),
+		IncludeOrgRoles:              boolParam(values, "include_org_roles", defaults.IncludeOrgRoles),
+		IncludeOrgDetails:            boolParam(values, "include_org_details", defaults.IncludeOrgDetails),
+
+		IncludeFollowerList:  boolParam(values, "include_follower_list", defaults.IncludeFollowerList),
+		IncludeFollowingList: boolParam(values, "include_following_list", defaults.IncludeFollowingList),
+
+		PushedSince: pushedSince,
+
+		IncludePrivate: boolParam(values, "include_private", defaults.IncludePrivate),
+		TopBy:          stringParam(values, "top_by", defaults.TopBy),
+
+		unknownPreset: unknownPreset,
+	}, nil
+}
+
+// lookupPreset Find a preset, the configured ones first.
+/*
+ * @param name string - The preset name
+ * @return IncludeOptions, bool - The options, found
+ */
+func (g *GStats) lookupPreset(name string) (IncludeOptions, bool) {
+	if preset, ok := g.config.Presets[name]; ok {
+		return preset, true
+	}
+	preset, ok := defaultPresets[name]
+	return preset, ok
+}
+
+// boolParam Parse a boolean query parameter.
+/*
+ * @param query url.Values - The query
+ * @param name string - The parameter name
+ * @param def bool - The value if the parameter is missing
+ * @return bool - The value
+ */
+func boolParam(query url.Values, name string, def bool) bool {
+	if !query.Has(name) {
+		return def
+	}
+	return query.Get(name) == "true"
+}
+
+// timeParam Parse a time query parameter, either a date or a duration before now like "72h" or "30d".
+/*
+ * @param query url.Values - The query
+ * @param name string - The parameter name
+ * @param def time.Time - The value if the parameter is missing
+ * @return time.Time, error - The value, the error if the parameter is invalid
+ */
+func timeParam(query url.Values, name string, def time.Time) (time.Time, error) {
+	value := query.Get(name)
+	if value == "" {
+		return def, nil
+	}
+	return parseTime(value)
+}
+
+// parseTime Parse a date or a duration before now like "72h" or "30d".
+/*
+ * @param value string - The value
+ * @return time.Time, error - The time, the error
+ */
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return time.Now().AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return time.Now().Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, expected a date or a duration like \"72h\" or \"30d\"", value)
+}
+
+// stringParam Get a string query parameter.
+/*
+ * @param query url.Values - The query
+ * @param name string - The parameter name
+ * @param def string - The value if the parameter is missing
+ * @return string - The value
+ */
+func stringParam(query url.Values, name string, def string) string {
+	if !query.Has(name) {
+		return def
+	}
+	return query.Get(name)
+}
+
+// intParam Parse an integer query parameter.
+/*
+ * @param query url.Values - The query
+ * @param name string - The parameter name
+ * @param def int - The value if the parameter is missing or invalid
+ * @return int - The value
+ */
+func intParam(query url.Values, name string, def int) int {
+	n, err := strconv.Atoi(query.Get(name))
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+// validateIncludeOptions Reject the option combinations that make no sense.
+/*
+ * Rules:
+ *  - include_contributors, include_languages, include_latest_release, include_commit_activity and include_profile_languages require include_repos
+ *  - heavy_min_stars requires one of them
+ *  - min_contributions requires include_contributors
+ *  - include_issue_breakdown requires include_repos
+ *  - include_org_roles and include_org_details require include_orgs
+ *
+ * @param opts IncludeOptions - The options
+ * @return error? - The error
+ */
+func validateIncludeOptions(opts IncludeOptions) error {
+	if opts.unknownPreset != "" {
+		return fmt.Errorf("unknown preset %q", opts.unknownPreset)
+	}
+	if opts.IncludeContributors && !opts.IncludeRepos {
+		return errors.New("include_contributors requires include_repos")
+	}
+	if opts.IncludeLanguages && !opts.IncludeRepos {
+		return errors.New("include_languages requires include_repos")
+	}
+	if opts.IncludeLatestRelease && !opts.IncludeRepos {
+		return errors.New("include_latest_release requires include_repos")
+	}
+	if opts.IncludeCommitActivity && !opts.IncludeRepos {
+		return errors.New("include_commit_activity requires include_repos")
+	}
+	if opts.IncludeProfileLanguages && !opts.IncludeRepos {
+		return errors.New("include_profile_languages requires include_repos")
+	}
+	if opts.HeavyMinStars > 0 && !opts.needsRepoDetails() && !opts.IncludeProfileLanguages {
+		return errors.New("heavy_min_stars requires include_contributors, include_languages, include_latest_release, include_commit_activity or include_profile_languages")
+	}
+	if opts.MinContributions > 0 && !opts.IncludeContributors {
+		return errors.New("min_contributions requires include_contributors")
+	}
+	if opts.IncludeIssueBreakdown && !opts.IncludeRepos {
+		return errors.New("include_issue_breakdown requires include_repos")
+	}
+	if opts.IncludeOrgRoles && !opts.IncludeOrgs {
+		return errors.New("include_org_roles requires include_orgs")
+	}
+	if opts.IncludeOrgDetails && !opts.IncludeOrgs {
+		return errors.New("include_org_details requires include_orgs")
+	}
+	if !opts.PushedSince.IsZero() && !opts.IncludeRepos {
+		return errors.New("pushed_since requires include_repos")
+	}
+	if opts.TopBy != "" && opts.TopBy != TopByStars && opts.TopBy != TopByScore {
+		return fmt.Errorf("top_by must be %q or %q", TopByStars, TopByScore)
+	}
+	if opts.TopBy != "" && !opts.IncludeRepos {
+		return errors.New("top_by requires include_repos")
+	}
+	if opts.IncludePrivate && !opts.needsRepos() {
+		return errors.New("include_private requires include_repos or include_stars")
+	}
+	return nil
+}
+
+// githubStatsHandler Handle the requests to get the GitHub stats.
+/*
+ * @param w http.ResponseWriter - The response writer
+ * @param r *http.Request - The request
+ * @param config Config - The configuration
+ * @return void
+ */
+func (g *GStats) githubStatsHandler(w http.ResponseWriter, r *http.Request, config Config) {
+	if !g.checkStrictParams(w, r, true, statsParams) {
+		return
+	}
+	query := r.URL.Query()
+	username := query.Get("username")
+	userID := query.Get("user_id")
+	usernames := parseUsernames(query.Get("usernames"))
+
+	if username == "" && userID == "" && len(usernames) == 0 {
+		writeError(w, r, "Le nom d'utilisateur est requis", http.StatusBadRequest)
+		return
+	}
+	if username != "" && userID != "" {
+		writeError(w, r, "username and user_id can't be used together", http.StatusBadRequest)
+		return
+	}
+	if len(usernames) > config.MaxBatchSize {
+		writeError(w, r, fmt.Sprintf("Too many usernames (max %d)", config.MaxBatchSize), http.StatusBadRequest)
+		return
+	}
+
+	// Check the request limit
+	if limiter := g.limiterFor(config.Path); !g.admit(r.Context(), limiter) {
+		g.writeRateLimited(w, r, limiter)
+		return
+	}
+
+	r = g.withUserClient(r)
+	format := query.Get("format")
+	feed := format == "rss" || format == "atom"
+	if feed && username == "" && userID == "" {
+		writeError(w, r, "The feed is only available for a single username", http.StatusBadRequest)
+		return
+	}
+
+	// Validated before resolving the user, an invalid request costs no GitHub call
+	var opts IncludeOptions
+	var expr ast.Expr
+	var err error
+	if !feed {
+		if opts, err = g.parseIncludeOptions(query); err != nil {
+			writeError(w, r, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := validateIncludeOptions(opts); err != nil {
+			writeError(w, r, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		if g.config.EmptyIncludeMode == EmptyIncludeReject && opts.isEmpty() {
+			writeError(w, r, "Request at least one section, e.g. include_stars=true", http.StatusBadRequest)
+			return
+		}
+		if src := query.Get("expr"); src != "" {
+			if expr, err = parseExpr(src); err != nil {
+				writeError(w, r, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	if userID != "" {
+		if username, err = g.resolveUserID(r.Context(), userID); err != nil {
+			if errors.Is(err, ErrInvalidUserID) {
+				writeError(w, r, err.Error(), http.StatusBadRequest)
+				return
+			}
+			g.writeStatsError(w, r, err)
+			return
+		}
+	}
+	if username, err = resolveUsername(r.Context(), username); err != nil {
+		g.writeStatsError(w, r, err)
+		return
+	}
+	for i := range usernames {
+		if usernames[i], err = resolveUsername(r.Context(), usernames[i]); err != nil {
+			g.writeStatsError(w, r, err)
+			return
+		}
+	}
+
+	if feed {
+		g.feedHandler(w, r, username, format)
+		return
+	}
+
+	if len(usernames) > 0 {
+		g.batchStatsHandler(w, r, usernames, opts, expr)
+		return
+	}
+
+	stats, stale, err := g.cachedStats(r.Context(), username, opts)
+	if err != nil {
+		g.writeStatsError(w, r, err)
+		return
+	}
+	if stats, err = computeExpr(stats, expr); err != nil {
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if stale {
+		w.Header().Set("X-Cache", "STALE")
+	}
+
+	writeData(w, r, stats.Username+"-stats", g.envelope(g.formatStats(stats)))
+}
+
+// batchStatsHandler Handle the requests to get the GitHub stats of several users at once.
+/*
+ * @param w http.ResponseWriter - The response writer
+ * @param r *http.Request - The request
+ * @param usernames []string - The usernames
+ * @param opts IncludeOptions - The options
+ * @param expr ast.Expr - The expr query parameter (nil if not set)
+ * @return void
+ */
+func (g *GStats) batchStatsHandler(w http.ResponseWriter, r *http.Request, usernames []string, opts IncludeOptions, expr ast.Expr) {
+	results, errs := g.fetchAll(r.Context(), usernames, opts)
+	for _, err := range errs {
+		if err != nil {
+			g.writeStatsError(w, r, err)
+			return
+		}
+	}
+
+	for i := range results {
+		stats, err := computeExpr(results[i], expr)
+		if err != nil {
+			writeError(w, r, fmt.Sprintf("%s: %v", usernames[i], err), http.StatusBadRequest)
+			return
+		}
+		results[i] = g.formatStats(stats)
+	}
+
+	writeData(w, r, "batch-stats", g.envelope(results))
+}
+
+// fetchAll Get the GitHub stats of several users with a bounded worker pool.
+/*
+ * @param ctx context.Context - The context
+ * @param usernames []string - The usernames
+ * @param opts IncludeOptions - The options
+ * @return []GitHubStats, []error - The stats and the error of each user, in the usernames order
+ */
+func (g *GStats) fetchAll(ctx context.Context, usernames []string, opts IncludeOptions) ([]GitHubStats, []error) {
+	results := make([]GitHubStats, len(usernames))
+	errs := make([]error, len(usernames))
+
+	// Each result keeps its request index
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < g.config.BatchConcurrency && worker < len(usernames); worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i], _, errs[i] = g.cachedStats(ctx, usernames[i], opts)
+			}
+		}()
+	}
+	for i := range usernames {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results, errs
+}
+
+// Stats Get the GitHub stats like the HTTP handler does, without serving HTTP: the call is rate limited,
+// the cache is looked up first and the fetched stats are cached.
+/*
+ * @param ctx context.Context - The context
+ * @param username string - The username
+ * @param opts IncludeOptions - The options
+ * @return GitHubStats, error - The stats, the error (ErrRequestLimitExceeded if rate limited)
+ */
+func (g *GStats) Stats(ctx context.Context, username string, opts IncludeOptions) (GitHubStats, error) {
+	if !g.admit(ctx, g.rateLimiter) {
+		return GitHubStats{}, ErrRequestLimitExceeded
+	}
+	stats, _, err := g.cachedStats(ctx, username, opts)
+	return stats, err
+}
+
+// cacheSchemaVersion Version of the cached stats layout, bumped whenever GitHubStats changes incompatibly.
+const cacheSchemaVersion = "v1"
+
+// cacheKey Get the cache key of the stats of a user fetched with some options.
+/*
+ * The key is namespaced with CacheKeyPrefix and the schema version, so an upgrade never reads old entries,
+ * and ends with a hash of the options, so stats fetched with fewer sections are never served to a request asking for more.
+ *
+ * @param username string - The username
+ * @param opts IncludeOptions - The options
+ * @return string - The key
+ */
+func (g *GStats) cacheKey(username string, opts IncludeOptions) string {
+	return g.config.CacheKeyPrefix + cacheSchemaVersion + ":" + username + ":" + optionsHash(opts)
+}
+
+// optionsHash Get a short hash of the options, equal for equal options.
+/*
+ * A relative pushed_since like "30d" moves with every request, it is rounded to the minute so the entry can still be hit.
+ *
+ * @param opts IncludeOptions - The options
+ * @return string - The hash
+ */
+func optionsHash(opts IncludeOptions) string {
+	opts.PushedSince = opts.PushedSince.Truncate(time.Minute)
+	// The exported fields are all plain values, the encoding can't fail
+	data, _ := json.Marshal(opts)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// refreshAhead Refresh a cache entry in the background when it expires within RefreshAheadWindow.
+/*
+ * A single refresh runs per key at a time.
+ *
+ * @param key string - The cache key
+ * @param username string - The username
+ * @param opts IncludeOptions - The options
+ * @return void
+ */
+func (g *GStats) refreshAhead(key string, username string, opts IncludeOptions) {
+	expiration, found := g.cache.Expiration(key)
+	if !found || time.Until(expiration) > g.config.RefreshAheadWindow {
+		return
+	}
+
+	g.refreshMu.Lock()
+	if g.refreshing[key] {
+		g.refreshMu.Unlock()
+		return
+	}
+	g.refreshing[key] = true
+	g.refreshMu.Unlock()
+
+	go func() {
+		defer func() {
+			g.refreshMu.Lock()
+			delete(g.refreshing, key)
+			g.refreshMu.Unlock()
+		}()
+
+		// Not tied to the request, which is already answered, but bounded like one, or by the window after which a request fetches anyway
+		timeout := g.config.HandlerTimeout
+		if timeout == 0 {
+			timeout = g.config.RefreshAheadWindow
+		}
+		ctx, cancel := withCallTimeout(context.Background(), timeout)
+		defer cancel()
+		stats, err := g.GetGitHubStatsContext(ctx, username, opts)
+		if err != nil || stats.Partial {
+			// The entry is kept until it expires, the next hit retries
+			return
+		}
+		g.setCached(key, g.withDeltas(key, stats))
+	}()
+}
+
+// withDeltas Set the deltas against the value being replaced in the cache, when there is one.
+/*
+ * Expired entries are kept in the cache, so the previous value is still there when refreshing.
+ *
+ * @param key string - The cache key
+ * @param stats GitHubStats - The new stats
+ * @return GitHubStats - The stats with their deltas
+ */
+func (g *GStats) withDeltas(key string, stats GitHubStats) GitHubStats {
+	if prev, found := g.cache.GetEntry(key); found && !prev.NotFound {
+		stats.Deltas = computeDeltas(prev.Stats, stats)
+	}
+	return stats
+}
+
+// cachedStats Get the GitHub stats from the cache, or fetch and cache them.
+/*
+ * @param ctx context.Context - The context
+ * @param username string - The username
+ * @param opts IncludeOptions - The options
+ * @return GitHubStats, bool, error - The stats, whether they are stale, the error
+ */
+func (g *GStats) cachedStats(ctx context.Context, username string, opts IncludeOptions) (GitHubStats, bool, error) {
+	// Nothing to fetch, and nothing worth caching
+	if g.config.EmptyIncludeMode == EmptyIncludeUsername && opts.isEmpty() {
+		if !usernamePattern.MatchString(username) {
+			return GitHubStats{}, false, ErrInvalidUsername
+		}
+		return GitHubStats{Username: username}, false, nil
+	}
+
+	// What the caller's token can see is not for everyone
+	if g.config.DisableCache || userClient(ctx) != nil || opts.IncludePrivate {
+		stats, err := g.GetGitHubStatsContext(ctx, username, opts)
+		return stats, false, err
+	}
+
+	// Check the cache
+	key := g.cacheKey(username, opts)
+	if entry, found := g.cache.GetEntry(key); found && !entry.NotFound && !time.Now().After(entry.Expiration) {
+		if g.config.RefreshAheadWindow > 0 {
+			g.refreshAhead(key, username, opts)
+		}
+		return g.refreshSections(ctx, key, username, opts, entry), false, nil
+	}
+	if g.cache.IsNotFound(key) {
+		return GitHubStats{}, false, ErrUserNotFound
+	}
+
+	stats, err := g.GetGitHubStatsContext(ctx, username, opts)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) && g.config.NegativeCacheDuration > 0 {
+			g.cache.SetNotFound(key, g.config.NegativeCacheDuration)
+		}
+		if g.config.ServeStaleOnError && isGitHubFailure(ctx, err) {
+			// Stale data beats an error while GitHub is down
+			entry, found := g.cache.GetEntry(key)
+			if found && !entry.NotFound && (g.config.MaxStaleDuration == 0 || entry.StaleFor() <= g.config.MaxStaleDuration) {
+				return entry.Stats, true, nil
+			}
+		}
+		return GitHubStats{}, false, err
+	}
+
+	stats = g.withDeltas(key, stats)
+
+	// Cache the stats, unless some sections are missing for lack of time
+	// The call budget would cut the next fetch short the same way, so those are cached briefly
+	if !stats.Partial {
+		g.setCached(key, stats)
+	} else if stats.BudgetLimited {
+		g.cache.Set(key, stats, g.config.PartialCacheDuration)
+	}
+	return stats, false, nil
+}
+
+// writeStatsError Write the HTTP error matching a stats retrieval error.
+/*
+ * @param w http.ResponseWriter - The response writer
+ * @param r *http.Request - The request
+ * @param err error - The error
+ * @return void
+ */
+func (g *GStats) writeStatsError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, ErrUserNotFound) {
+		writeError(w, r, "User not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, ErrInvalidUsername) {
+		writeError(w, r, "Invalid username", http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, ErrUserSuspended) {
+		writeError(w, r, "User suspended or deleted", g.config.SuspendedStatusCode)
+		return
+	}
+	if errors.Is(err, ErrRequestLimitExceeded) {
+		g.writeRateLimited(w, r, g.rateLimiter)
+		return
+	}
+	if errors.Is(err, ErrUserTokenRequired) || errors.Is(err, ErrInvalidUserToken) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeError(w, r, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if errors.Is(err, ErrCircuitOpen) {
+		g.setRetryAfter(w, g.breaker.RetryAfter())
+		writeError(w, r, "GitHub is currently unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if errors.Is(err, ErrRateLimited) {
+		g.setRetryAfter(w, gitHubRetryAfter(err))
+		writeError(w, r, "GitHub rate limit exceeded", http.StatusTooManyRequests)
+		return
+	}
+	if errors.Is(err, ErrGitHubUnavailable) {
+		// One second while the breaker is closed, the rest of its cooldown once open
+		g.setRetryAfter(w, g.breaker.RetryAfter())
+		writeError(w, r, "GitHub is currently unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	writeError(w, r, "Erreur lors de la récupération des données", http.StatusInternalServerError)
+}
+
+// parseUsernames Parse a comma-separated list of usernames, dropping duplicates.
+/*
+ * @param list string - The list
+ * @return []string - The usernames
+ */
+func parseUsernames(list string) []string {
+	var usernames []string
+	seen := make(map[string]bool)
+	for _, username := range strings.Split(list, ",") {
+		username = strings.TrimSpace(username)
+		// GitHub logins are case-insensitive
+		key := strings.ToLower(username)
+		if username == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		usernames = append(usernames, username)
+	}
+	return usernames
+}
+
+// GetGitHubStats Get the GitHub stats for a given user according to the specified options.
+/*
+ * @param username string - The username
+ * @param opts IncludeOptions - The options
+ * @return GitHubStats, error - The stats, the error (ErrUserNotFound, ErrRateLimited or ErrGitHubUnavailable with errors.Is)
+ */
+func (g *GStats) GetGitHubStats(username string, opts IncludeOptions) (GitHubStats, error) {
+	return g.GetGitHubStatsContext(context.Background(), username, opts)
+}
+
+// GetGitHubStatsContext Get the GitHub stats like GetGitHubStats, aborting the GitHub calls when the context is done.
+/*
+ * @param ctx context.Context - The context
+ * @param username string - The username
+ * @param opts IncludeOptions - The options
+ * @return GitHubStats, error - The stats, the error
+ */
+func (g *GStats) GetGitHubStatsContext(ctx context.Context, username string, opts IncludeOptions) (GitHubStats, error) {
+	// Served even when GitHub is unreachable
+	if stats, found := g.staticStats(username); found {
+		return stats, nil
+	}
+	if g.config.MaxRetriesPerRequest > 0 {
+		ctx = withRetryBudget(ctx, g.config.MaxRetriesPerRequest)
+	}
+	if g.config.MaxAPICallsPerRequest > 0 {
+		ctx = withCallBudget(ctx, g.config.MaxAPICallsPerRequest)
+	}
+
+	var stats GitHubStats
+	err := g.guardedCall(ctx, func() error {
+		var err error
+		stats, err = g.fetchGitHubStats(ctx, username, opts)
+		return err
+	})
+	return stats, err
+}
+
+// guardedCall Make GitHub calls behind the circuit breaker, their outcome being recorded by the breaker and the error rate.
+/*
+ * Used by every GitHub call of the server, not only the stats fetches.
+ *
+ * @param ctx context.Context - The context
+ * @param call func() error - The calls
+ * @return error - The error of the calls, wrapped by classifyError
+ */
+func (g *GStats) guardedCall(ctx context.Context, call func() error) error {
+	if !g.breaker.Allow() {
+		return fmt.Errorf("%w: %w", ErrGitHubUnavailable, ErrCircuitOpen)
+	}
+
+	err := call()
+	failed := err != nil && isGitHubFailure(ctx, err)
+	if failed {
+		g.breaker.Failure()
+	} else {
+		g.breaker.Success()
+	}
+	g.errorRate.Record(failed)
+	return classifyError(ctx, err)
+}
+
+// classifyError Wrap a GitHub error with ErrRateLimited or ErrGitHubUnavailable so callers can use errors.Is.
+/*
+ * @param ctx context.Context - The context of the request
+ * @param err error - The error
+ * @return error - The classified error
+ */
+func classifyError(ctx context.Context, err error) error {
+	var rateLimitErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &rateLimitErr), errors.As(err, &abuseErr):
+		return fmt.Errorf("%w: %w", ErrRateLimited, err)
+	case isGitHubFailure(ctx, err) && !errors.Is(err, ErrUserNotFound):
+		return fmt.Errorf("%w: %w", ErrGitHubUnavailable, err)
+	}
+	return err
+}
+
+// fetchGitHubStats Fetch the GitHub stats from the GitHub API.
+/*
+ * Only the calls needed by the options are made, see the "GitHub API calls" table of the README.
+ *
+ * @param ctx context.Context - The context
+ * @param username string - The username
+ * @param opts IncludeOptions - The options
+ * @return GitHubStats, error - The stats, the error
+ */
+func (g *GStats) fetchGitHubStats(ctx context.Context, username string, opts IncludeOptions) (GitHubStats, error) {
+	client := g.clientFor(ctx)
+	deadline := g.sectionsDeadline(ctx)
+
+	userCtx, cancel := withCallTimeout(ctx, g.config.UserTimeout)
+	user, _, err := client.Users.Get(userCtx, username)
+	cancel()
+	if isNotFound(err) {
+		return GitHubStats{}, fmt.Errorf("%w: %w", ErrUserNotFound, err)
+	}
+	if isSuspended(user, err) {
+		if err == nil {
+			return GitHubStats{}, ErrUserSuspended
+		}
+		return GitHubStats{}, fmt.Errorf("%w: %w", ErrUserSuspended, err)
+	}
+	if err != nil {
+		return GitHubStats{}, err
+	}
+
+	stats := GitHubStats{
+		Username:    username,
+		AccountType: user.GetType(),
+		// The full count, even when the repository list is truncated
+		TotalRepositories: user.GetPublicRepos(),
+	}
+	isOrg := g.config.OrgAwareFetch && stats.AccountType == AccountTypeOrganization
+
+	if opts.IncludeFollowers {
+		stats.Followers = *user.Followers
+	}
+	if opts.IncludeFollowing {
+		stats.Following = *user.Following
+	}
+
+	// The user payload is always returned, the other sections are skipped once the time budget is exhausted
+	budget := callBudgetFrom(ctx)
+	skip := func(section string) bool {
+		if budget.exhausted() {
+			stats.Partial = true
+			stats.BudgetLimited = true
+			stats.Warnings = append(stats.Warnings, section+": skipped, the GitHub call budget is exhausted")
+			return true
+		}
+		if ctx.Err() == nil && (deadline.IsZero() || time.Now().Before(deadline)) {
+			return false
+		}
+		stats.Partial = true
+		stats.Warnings = append(stats.Warnings, section+": skipped, the response time budget is exhausted")
+		return true
+	}
+
+	// The sections without their own timeout get GitHubTimeout, shared by their pages
+	if opts.IncludeFollowerList && !skip("follower_logins") {
+		callCtx, cancel := withCallTimeout(ctx, g.config.GitHubTimeout)
+		stats.FollowerLogins, err = fetchLogins(callCtx, client.Users.ListFollowers, username, g.config.MaxFollowLogins)
+		cancel()
+		if err != nil {
+			return budgetCut(&stats, err)
+		}
+	}
+	if opts.IncludeFollowingList && !skip("following_logins") {
+		callCtx, cancel := withCallTimeout(ctx, g.config.GitHubTimeout)
+		stats.FollowingLogins, err = fetchLogins(callCtx, client.Users.ListFollowing, username, g.config.MaxFollowLogins)
+		cancel()
+		if err != nil {
+			return budgetCut(&stats, err)
+		}
+	}
+	// Followers and following come from the user payload, skip the repository listing when possible
+	if opts.needsRepos() && !skip("repositories") {
+		// Private repositories can only be listed as the authenticated user
+		listUser, listOpts := username, (*github.RepositoryListOptions)(nil)
+		if opts.IncludePrivate && userClient(ctx) == nil && !g.config.AllowPrivateWithServerToken {
+			// Anyone could read the private repositories of the server token owner
+			stats.Warnings = append(stats.Warnings, "include_private: the request must send its own GitHub token, only the public repositories are listed")
+		} else if opts.IncludePrivate {
+			ownerCtx, cancel := withCallTimeout(ctx, g.config.GitHubTimeout)
+			owner, err := isTokenOwner(ownerCtx, client, username)
+			cancel()
+			if err != nil {
+				return budgetCut(&stats, err)
+			}
+			if owner {
+				listUser, listOpts = "", &github.RepositoryListOptions{Visibility: "all", Affiliation: "owner"}
+			} else {
+				stats.Warnings = append(stats.Warnings, "include_private: the GitHub token doesn't belong to "+username+", only the public repositories are listed")
+			}
+		}
+
+		reposCtx, cancel := withCallTimeout(ctx, g.config.ReposTimeout)
+		var repos []*github.Repository
+		if isOrg {
+			// Listed with the organization endpoint, the private repositories are left out as the token never belongs to it
+			repos, _, err = client.Repositories.ListByOrg(reposCtx, username, &github.RepositoryListByOrgOptions{Type: "public"})
+		} else {
+			repos, _, err = client.Repositories.List(reposCtx, listUser, listOpts)
+		}
+		cancel()
+		if err != nil {
+			return budgetCut(&stats, err)
+		}
+
+		// A requested section without data is [], a disabled one stays null
+		if opts.IncludeRepos {
+			stats.Repositories = []RepoStats{}
+		}
+		// Rank before truncating, so the N repositories are the top ones
+		if opts.TopBy != "" {
+			rankRepos(repos, opts.TopBy, g.config.ScoreWeights)
+		}
+
+		// The details of the remaining repositories are left out once the budget is exhausted
+		budgetLimited := func() {
+			if !stats.BudgetLimited {
+				stats.Partial = true
+				stats.BudgetLimited = true
+				stats.Warnings = append(stats.Warnings, "repositories: the details are cut short, the GitHub call budget is exhausted")
+			}
+		}
+		breakdowns := 0
+		var computing []computingRepo
+		for _, repo := range repos {
+			if opts.IncludeStars {
+				stats.TotalStars += *repo.StargazersCount
+			}
+			if opts.IncludeRepos {
+				// Filter before truncating, so the N repositories are all recent ones
+				if !opts.PushedSince.IsZero() && !repo.GetPushedAt().After(opts.PushedSince) {
+					continue
+				}
+				if limit := opts.firstNRepos(); limit != AllRepos && len(stats.Repositories) >= limit {
+					// Don't break, the stars of the remaining repositories still count
+					continue
+				}
+				repoStats := RepoStats{
+					Name:      *repo.Name,
+					Stars:     *repo.StargazersCount,
+					Forks:     *repo.ForksCount,
+					CreatedAt: JSONTime{Time: repo.GetCreatedAt().Time},
+					UpdatedAt: JSONTime{Time: repo.GetUpdatedAt().Time},
+					PushedAt:  JSONTime{Time: repo.GetPushedAt().Time},
+
+					DefaultBranch: repo.GetDefaultBranch(),
+					Private:       repo.GetPrivate(),
+				}
+				// Each detail costs one call per repository, without any the budget isn't even checked
+				if opts.needsRepoDetails() && repoStats.Stars >= opts.HeavyMinStars {
+					err := ErrCallBudgetExhausted
+					if !budget.exhausted() {
+						detailsCtx, cancel := withCallTimeout(ctx, g.config.GitHubTimeout)
+						err = fetchRepoDetails(detailsCtx, client, repo.GetOwner().GetLogin(), &repoStats, opts, g.config.MaxContributorsPerRepo)
+						cancel()
+					}
+					// The listing is already paid for, only the details of the remaining repositories are left out
+					if errors.Is(err, ErrCallBudgetExhausted) {
+						budgetLimited()
+					} else if err != nil {
+						return GitHubStats{}, err
+					} else if opts.IncludeCommitActivity && repoStats.WeeklyCommits == nil {
+						computing = append(computing, computingRepo{index: len(stats.Repositories), owner: repo.GetOwner().GetLogin()})
+					}
+				}
+				// Each breakdown costs a GraphQL query, hence the bound
+				if opts.IncludeIssueBreakdown && breakdowns < g.config.MaxIssueBreakdownRepos {
+					breakdowns++
+					err := ErrCallBudgetExhausted
+					if !budget.exhausted() {
+						breakdownCtx, cancel := withCallTimeout(ctx, g.config.GitHubTimeout)
+						repoStats.IssueBreakdown, err = fetchIssueBreakdown(breakdownCtx, client, repo.GetOwner().GetLogin(), repo.GetName())
+						cancel()
+					}
+					if errors.Is(err, ErrCallBudgetExhausted) {
+						budgetLimited()
+					} else if err != nil {
+						// The breakdown is left null, it doesn't fail the repository
+						stats.Partial = true
+						stats.Warnings = append(stats.Warnings, fmt.Sprintf("repositories: the issue breakdown of %s failed: %v", repoStats.Name, err))
+					}
+				}
+				stats.Repositories = append(stats.Repositories, repoStats)
+			}
+		}
+
+		// GitHub computes the commit activity in the background, the repositories still computing are retried together
+		if len(computing) > 0 {
+			computing, err = g.retryWeeklyCommits(ctx, client, stats.Repositories, computing, deadline)
+			if errors.Is(err, ErrCallBudgetExhausted) {
+				budgetLimited()
+			} else if err != nil {
+				return GitHubStats{}, err
+			}
+		}
+		if len(computing) > 0 {
+			// Left out of the cache, a later request gets them
+			stats.Partial = true
+			stats.Warnings = append(stats.Warnings, fmt.Sprintf("repositories: GitHub is still computing the commit activity of %d repositories", len(computing)))
+		}
+
+		if opts.IncludeLanguages {
+			stats.TopLanguages = rankLanguages(stats.Repositories, g.config.MaxTopLanguages)
+		}
+		// Over every listed repository, not only the returned ones
+		if opts.IncludeProfileLanguages && !skip("profile_languages") {
+			totals, err := fetchProfileLanguages(ctx, client, repos, stats.Repositories, opts.HeavyMinStars, g.config.MaxLanguageRepos, g.config.GitHubTimeout)
+			if errors.Is(err, ErrCallBudgetExhausted) {
+				budgetLimited()
+			} else if err != nil {
+				return GitHubStats{}, err
+			}
+			stats.ProfileLanguages = rankLanguageBytes(totals, 0)
+		}
+	}
+
+	// An organization can't be a member of organizations
+	if opts.IncludeOrgs && isOrg {
+		stats.Organizations = []string{}
+	} else if opts.IncludeOrgs && !skip("organizations") {
+		stats.Organizations = []string{}
+		listOpts := &github.ListOptions{PerPage: 100}
+		missingScope := false
+		avatars := make(map[string]string)
+		for page := 0; page < g.config.MaxOrgPages; page++ {
+			orgsCtx, cancel := withCallTimeout(ctx, g.config.OrgsTimeout)
+			orgs, resp, err := client.Organizations.List(orgsCtx, username, listOpts)
+			cancel()
+			if isMissingScope(err, "read:org") {
+				// Don't fail the whole request, the other sections are still valid
+				stats.Organizations = nil
+				stats.Warnings = append(stats.Warnings, "organizations: the GitHub token is missing the read:org scope")
+				missingScope = true
+				break
+			}
+			if err != nil {
+				return budgetCut(&stats, err)
+			}
+
+			for _, org := range orgs {
+				stats.Organizations = append(stats.Organizations, *org.Login)
+				avatars[*org.Login] = org.GetAvatarURL()
+			}
+
+			if resp.NextPage == 0 {
+				break
+			}
+			listOpts.Page = resp.NextPage
+		}
+
+		if (opts.IncludeOrgRoles || opts.IncludeOrgDetails) && !missingScope {
+			details := make([]OrgInfo, 0, len(stats.Organizations))
+			for _, org := range stats.Organizations {
+				details = append(details, OrgInfo{Login: org})
+			}
+			if opts.IncludeOrgRoles {
+				rolesCtx, cancel := withCallTimeout(ctx, g.config.GitHubTimeout)
+				details, err = fetchOrgRoles(rolesCtx, client, username, stats.Organizations)
+				cancel()
+				if err != nil {
+					return budgetCut(&stats, err)
+				}
+			}
+			if opts.IncludeOrgDetails {
+				detailsCtx, cancel := withCallTimeout(ctx, g.config.GitHubTimeout)
+				err := fetchOrgDetails(detailsCtx, client, details, avatars, g.config.MaxOrgDetails)
+				cancel()
+				if err != nil {
+					return budgetCut(&stats, err)
+				}
+			}
+			stats.OrganizationDetails = details
+		}
+	}
+
+	if opts.IncludeProfileReadme && !skip("profile_readme") {
+		readmeCtx, cancel := withCallTimeout(ctx, g.config.GitHubTimeout)
+		readme, err := fetchProfileReadme(readmeCtx, client, username)
+		cancel()
+		if err != nil {
+			return budgetCut(&stats, err)
+		}
+		stats.ProfileReadme = readme
+	}
+
+	if opts.IncludeExternalContributions && !skip("contributed_repositories") {
+		searchCtx, cancel := withCallTimeout(ctx, g.config.GitHubTimeout)
+		contributed, err := fetchContributedRepos(searchCtx, client, username, g.config.MaxContributedRepos)
+		cancel()
+		if err != nil {
+			return budgetCut(&stats, err)
+		}
+		stats.ContributedRepositories = contributed
+	}
+
+	if opts.IncludeActivity && !skip("activity") {
+		eventsCtx, cancel := withCallTimeout(ctx, g.config.GitHubTimeout)
+		stats.Activity, err = fetchActivity(eventsCtx, client, username, g.config.MaxFeedItems)
+		cancel()
+		if err != nil {
+			return budgetCut(&stats, err)
+		}
+	}
+
+	if opts.IncludeStarred && !skip("starred_repositories") {
+		starredCtx, cancel := withCallTimeout(ctx, g.config.GitHubTimeout)
+		stats.StarredRepositories, err = fetchStarredRepos(starredCtx, client, username, g.config.MaxStarredRepos)
+		cancel()
+		if err != nil {
+			return budgetCut(&stats, err)
+		}
+	}
+
+	if opts.IncludeStreak && !skip("streaks") {
+		graphQLCtx, cancel := withCallTimeout(ctx, g.config.GitHubTimeout)
+		current, longest, err := fetchStreaks(graphQLCtx, client, username)
+		cancel()
+		if err != nil {
+			return budgetCut(&stats, err)
+		}
+		stats.CurrentStreak, stats.LongestStreak = current, longest
+	}
+
+	if opts.IncludeSponsors && !skip("sponsors") {
+		graphQLCtx, cancel := withCallTimeout(ctx, g.config.GitHubTimeout)
+		stats.Sponsors, err = fetchSponsors(graphQLCtx, client, username)
+		cancel()
+		if err != nil {
+			return budgetCut(&stats, err)
+		}
+	}
+
+	for i, compute := range g.config.CustomComputers {
+		if skip(fmt.Sprintf("custom computer %d", i)) {
+			continue
+		}
+		if err := compute(ctx, client, username, &stats); err != nil {
+			stats.Warnings = append(stats.Warnings, fmt.Sprintf("custom computer %d: %v", i, err))
+		}
+	}
+
+	return stats, nil
+}
+
+// sectionsDeadline Get the time after which no new optional section is fetched.
+/*
+ * The earliest of ResponseBudget from now and DeadlineMargin before the request deadline, so what was fetched is answered in time.
+ *
+ * @param ctx context.Context - The context of the request
+ * @return time.Time - The deadline, zero if none
+ */
+func (g *GStats) sectionsDeadline(ctx context.Context) time.Time {
+	deadline := time.Time{}
+	if g.config.ResponseBudget > 0 {
+		deadline = time.Now().Add(g.config.ResponseBudget)
+	}
+	if requestDeadline, ok := ctx.Deadline(); ok {
+		requestDeadline = requestDeadline.Add(-g.config.DeadlineMargin)
+		if deadline.IsZero() || requestDeadline.Before(deadline) {
+			deadline = requestDeadline
+		}
+	}
+	return deadline
+}
+
+// withCallTimeout Derive the context of a single GitHub call.
+/*
+ * The effective deadline is the earliest of the request one and the timeout, a timeout never extends the request.
+ *
+ * @param ctx context.Context - The request context
+ * @param timeout time.Duration - The timeout of the call (none if 0)
+ * @return context.Context, context.CancelFunc - The context, its cancel function
+ */
+func withCallTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	deadline := time.Now().Add(timeout)
+	if parent, ok := ctx.Deadline(); ok && !deadline.Before(parent) {
+		// Already tighter, no need for another timer
+		return ctx, func() {}
+	}
+	return context.WithDeadline(ctx, deadline)
+}
+
+// fetchRepoDetails Fetch the contributors, languages, latest release and commit activity of a repository according to the options.
+/*
+ * @param ctx context.Context - The context
+ * @param client *github.Client - The GitHub client
+ * @param owner string - The repository owner
+ * @param repo *RepoStats - The repository stats to fill
+ * @param opts IncludeOptions - The options
+ * @param maxContributors int - The maximum number of contributors kept, the top ones (unlimited if 0)
+ * @return error? - The error
+ */
+func fetchRepoDetails(ctx context.Context, client *github.Client, owner string, repo *RepoStats, opts IncludeOptions, maxContributors int) error {
+	if opts.IncludeContributors {
+		contributors, _, err := client.Repositories.ListContributors(ctx, owner, repo.Name, nil)
+		if err != nil {
+			return err
+		}
+		repo.Contributors = make(map[string]int, len(contributors))
+		for _, contributor := range contributors {
+			// Anonymous contributors have no login
+			if contributor.Login != nil {
+				repo.Contributors[*contributor.Login] = contributor.GetContributions()
+			}
+		}
+		if maxContributors > 0 && len(repo.Contributors) > maxContributors {
+			repo.Contributors = topContributors(repo.Contributors, maxContributors)
+		}
+		for login, contributions := range repo.Contributors {
+			if contributions < opts.MinContributions {
+				delete(repo.Contributors, login)
+			}
+		}
+	}
+
+	if opts.IncludeLanguages {
+		languages, _, err := client.Repositories.ListLanguages(ctx, owner, repo.Name)
+		if err != nil {
+			return err
+		}
+		repo.Languages = languages
+	}
+
+	if opts.IncludeLatestRelease {
+		release, err := fetchLatestRelease(ctx, client, owner, repo.Name)
+		if err != nil {
+			return err
+		}
+		repo.LatestRelease = release
+	}
+
+	if opts.IncludeCommitActivity {
+		commits, err := fetchWeeklyCommits(ctx, client, owner, repo.Name)
+		if err != nil {
+			return err
+		}
+		repo.WeeklyCommits = commits
+	}
+
+	return nil
+}
+
+// topContributors Keep the contributors with the most contributions.
+/*
+ * @param contributors map[string]int - The contributions by login
+ * @param limit int - The number of contributors to keep
+ * @return map[string]int - The top contributors
+ */
+func topContributors(contributors map[string]int, limit int) map[string]int {
+	top := make(map[string]int, limit)
+	for _, login := range sortedByContributions(contributors)[:limit] {
+		top[login] = contributors[login]
+	}
+	return top
+}
+
+// sortedByContributions Get the logins sorted by contributions, most first.
+/*
+ * @param contributors map[string]int - The contributions by login
+ * @return []string - The logins
+ */
+func sortedByContributions(contributors map[string]int) []string {
+	logins := make([]string, 0, len(contributors))
+	for login := range contributors {
+		logins = append(logins, login)
+	}
+	// Ties are broken by login so the result doesn't depend on the map order
+	sort.Slice(logins, func(i, j int) bool {
+		if contributors[logins[i]] != contributors[logins[j]] {
+			return contributors[logins[i]] > contributors[logins[j]]
+		}
+		return logins[i] < logins[j]
+	})
+	return logins
+}
+
+// fetchProfileReadme Fetch the decoded README of the username/username repository.
+/*
+ * @param ctx context.Context - The context
+ * @param client *github.Client - The GitHub client
+ * @param username string - The username
+ * @return string, error - The README content (empty if the user has none), the error
+ */
+func fetchProfileReadme(ctx context.Context, client *github.Client, username string) (string, error) {
+	readme, _, err := client.Repositories.GetReadme(ctx, username, username, nil)
+	if isNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return readme.GetContent()
+}
+
+// isNotFound Check if the error is a GitHub 404.
+/*
+ * @param err error - The error
+ * @return bool - The result
+ */
+func isNotFound(err error) bool {
+	var errResp *github.ErrorResponse
+	return errors.As(err, &errResp) && errResp.Response != nil && errResp.Response.StatusCode == http.StatusNotFound
+}
+
+// Client Get the authenticated GitHub client, nil before Connect or NewGStats.
+/*
+ * The client is shared with the server: calls made with it consume the same GitHub quota.
+ * With several tokens, this is the client of the first one.
+ *
+ * @return *github.Client - The client
+ */
+func (g *GStats) Client() *github.Client {
+	if g == nil {
+		return nil
+	}
+	return g.client
+}
+
+// wrapHandler Wrap a handler with the timeout and access log middlewares.
+/*
+ * @param h http.HandlerFunc - The handler
+ * @return http.Handler - The wrapped handler
+ */
+func (g *GStats) wrapHandler(h http.HandlerFunc) http.Handler {
+	var handler http.Handler = h
+	if g.config.HandlerTimeout > 0 {
+		// The request context is canceled on timeout, which aborts the pending GitHub calls
+		handler = http.TimeoutHandler(handler, g.config.HandlerTimeout, "Request timed out")
+	}
+	if g.accessLog != nil {
+		handler = g.accessLog.wrap(handler)
+	}
+	return withRequestID(handler)
+}
+
+// warmOnStart Fetch and cache the users one at a time, logging the failures.
+/*
+ * @param usernames []string - The usernames
+ * @return void
+ */
+func (g *GStats) warmOnStart(usernames []string) {
+	// Only the forced parameters, already validated by setup
+	opts, err := g.parseIncludeOptions(url.Values{})
+	if err != nil {
+		log.Printf("githubstats: failed to warm the cache: %v", err)
+		return
+	}
+	for _, username := range parseUsernames(strings.Join(usernames, ",")) {
+		if _, _, err := g.cachedStats(context.Background(), username, opts); err != nil {
+			if g.config.RedactUsernamesInLogs {
+				log.Printf("githubstats: failed to warm the cache for %s: %s", redactUsername(g.redactionKey, username), redactError(g.redactionKey, err, username))
+			} else {
+				log.Printf("githubstats: failed to warm the cache for %s: %v", username, err)
+			}
+		}
+	}
+}
+
+// NewGStats Create an instance with its GitHub clients, cache, rate limiter and circuit breaker, without serving HTTP.
+/*
+ * Stats, GetGitHubStats and Handler can be used right away, e.g. by a CLI tool.
+ *
+ * @param config Config - The configuration
+ * @return *GStats, error - The instance, the error
+ */
+func NewGStats(config Config) (*GStats, error) {
+	g := &GStats{}
+	if err := g.setup(config); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Handler Get the HTTP handler serving the routes of the instance, to mount it on another server.
+/*
+ * @return http.Handler - The handler
+ */
+func (g *GStats) Handler() http.Handler {
+	return g.mux
+}
+
+// Connect initialise le client GitHub avec le token et configure le serveur.
+/*
+ * @param config Config - The configuration
+ * @return error? - The error
+ */
+func (g *GStats) Connect(config Config) error {
+	if err := g.setup(config); err != nil {
+		return err
+	}
+	// With the default values
+	config = g.config
+
+	// Nothing would keep the warmed users
+	if len(config.WarmOnStart) > 0 && !config.DisableCache {
+		go g.warmOnStart(config.WarmOnStart)
+	}
+
+	return g.serve(config)
+}
+
+// setup Apply the default values of the configuration and create the clients, cache, limiters and routes.
+/*
+ * @param config Config - The configuration
+ * @return error? - The error
+ */
+func (g *GStats) setup(config Config) error {
+	var sources []oauth2.TokenSource
+	for _, token := range append([]string{config.Token}, config.Tokens...) {
+		if token != "" {
+			sources = append(sources, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}))
+		}
+	}
+	if config.TokenSource != nil {
+		// Reuse the token until it expires, then ask the source for a new one
+		sources = append(sources, oauth2.ReuseTokenSource(nil, config.TokenSource))
+	}
+
+	// Check if the token is defined
+	if len(sources) == 0 {
+		return fmt.Errorf("le token GitHub doit être défini")
 	}
 	if config.IP == "" {
 		config.IP = "0.0.0.0" // Default value
@@ -311,36 +2270,318 @@ func (g *GStats) Connect(config Config) error {
 	if config.Scheme == "" {
 		config.Scheme = "http" // Default value
 	}
+	if config.Scheme != "http" && config.Scheme != "https" {
+		return fmt.Errorf("githubstats: invalid Scheme %q, expected \"http\" or \"https\"", config.Scheme)
+	}
 	if config.Path == "" {
 		config.Path = "/stats" // Default value
 	}
+	if config.ComparePath == "" {
+		config.ComparePath = "/compare" // Default value
+	}
+	if config.ReposPath == "" {
+		config.ReposPath = "/repos" // Default value
+	}
+	if config.MaxReposPerRequest == 0 {
+		config.MaxReposPerRequest = 50 // Default value
+	}
+	if config.HealthPath == "" {
+		config.HealthPath = "/healthz" // Default value
+	}
+	if config.ReadinessPath == "" {
+		config.ReadinessPath = "/readyz" // Default value
+	}
+	if config.ReadinessTimeout == 0 {
+		config.ReadinessTimeout = 2 * time.Second // Default value
+	}
+	if config.OrgPath == "" {
+		config.OrgPath = "/org/" // Default value
+	}
+	if !strings.HasSuffix(config.OrgPath, "/") {
+		// The organization is part of the path
+		config.OrgPath += "/"
+	}
 	if config.RateLimit == 0 {
 		config.RateLimit = 10 // Default value
 	}
 	if config.CacheDuration == 0 {
 		config.CacheDuration = 1 * time.Hour // Default value
 	}
+	if config.PartialCacheDuration == 0 {
+		config.PartialCacheDuration = min(time.Minute, config.CacheDuration) // Default value
+	}
+	for name, duration := range config.SectionCacheDurations {
+		if _, ok := cacheSections[name]; !ok || duration <= 0 {
+			return fmt.Errorf("githubstats: invalid SectionCacheDurations entry %q: %v", name, duration)
+		}
+	}
+	// Every hit would be refreshed
+	if config.RefreshAheadWindow >= config.CacheDuration {
+		return fmt.Errorf("githubstats: RefreshAheadWindow %v must be shorter than CacheDuration %v", config.RefreshAheadWindow, config.CacheDuration)
+	}
+	if config.MaxContributedRepos == 0 {
+		config.MaxContributedRepos = 10 // Default value
+	}
+	if config.MaxStarredRepos == 0 {
+		config.MaxStarredRepos = 30 // Default value
+	}
+	if config.MaxTopLanguages == 0 {
+		config.MaxTopLanguages = 5 // Default value
+	}
+	if config.MaxLanguageRepos == 0 {
+		config.MaxLanguageRepos = 30 // Default value
+	}
+	if config.MaxFeedItems == 0 {
+		config.MaxFeedItems = 20 // Default value
+	}
+	if config.ScoreWeights == (ScoreWeights{}) {
+		config.ScoreWeights = defaultScoreWeights // Default value
+	}
+	if config.MaxFollowLogins == 0 {
+		config.MaxFollowLogins = 100 // Default value
+	}
+	if config.CommitActivityRetries == 0 {
+		config.CommitActivityRetries = 3 // Default value
+	}
+	if config.CommitActivityRetryDelay == 0 {
+		config.CommitActivityRetryDelay = time.Second // Default value
+	}
+	if config.AdminPath == "" {
+		config.AdminPath = "/admin" // Default value
+	}
+	if config.MaxRequestBodySize == 0 {
+		config.MaxRequestBodySize = 1 << 20 // Default value (1 MiB)
+	}
+	if config.MaxOrgPages == 0 {
+		config.MaxOrgPages = 10 // Default value
+	}
+	if config.MaxIssueBreakdownRepos == 0 {
+		config.MaxIssueBreakdownRepos = 5 // Default value
+	}
+	if config.MaxOrgDetails == 0 {
+		config.MaxOrgDetails = 10 // Default value
+	}
+	if config.MaxOrgMembers == 0 {
+		config.MaxOrgMembers = 100 // Default value
+	}
+	if config.MaxOrgRepos == 0 {
+		config.MaxOrgRepos = 100 // Default value
+	}
+	if config.MaxContributorPages == 0 {
+		config.MaxContributorPages = 5 // Default value
+	}
+	if config.MaxTopContributors == 0 {
+		config.MaxTopContributors = 10 // Default value
+	}
+	if config.BreakerThreshold == 0 {
+		config.BreakerThreshold = 5 // Default value
+	}
+	if config.MaxBatchSize == 0 {
+		config.MaxBatchSize = 10 // Default value
+	}
+	if config.BatchConcurrency == 0 {
+		config.BatchConcurrency = 4 // Default value
+	}
+	if config.BatchConcurrency < 1 {
+		// No worker would ever take the jobs
+		return fmt.Errorf("githubstats: invalid BatchConcurrency %d", config.BatchConcurrency)
+	}
+	if config.BreakerCooldown == 0 {
+		config.BreakerCooldown = 30 * time.Second // Default value
+	}
+	if config.SuspendedStatusCode == 0 {
+		config.SuspendedStatusCode = http.StatusGone // Default value
+	}
+	// Below 1 the stats fetched during an outage would expire sooner, hitting GitHub harder while it recovers
+	if config.ErrorTTLMultiplier != 0 && config.ErrorTTLMultiplier < 1 {
+		return fmt.Errorf("githubstats: invalid ErrorTTLMultiplier %v", config.ErrorTTLMultiplier)
+	}
+	// The limit of a shared cache is set by whoever created it, a different one would be silently ignored
+	if config.Cache != nil && config.MaxCacheEntrySize != 0 && config.MaxCacheEntrySize != config.Cache.MaxEntrySize() {
+		return fmt.Errorf("githubstats: MaxCacheEntrySize %d conflicts with the %d bytes of the shared Cache, set it with Cache.SetMaxEntrySize", config.MaxCacheEntrySize, config.Cache.MaxEntrySize())
+	}
+	if config.ErrorRateWindow == 0 {
+		config.ErrorRateWindow = 5 * time.Minute // Default value
+	}
+	if config.DegradedStatusCode == 0 {
+		config.DegradedStatusCode = http.StatusOK // Default value
+	}
+	if config.DeadlineMargin == 0 {
+		config.DeadlineMargin = 500 * time.Millisecond // Default value
+	}
+	if config.UserTimeout == 0 {
+		config.UserTimeout = config.GitHubTimeout // Default value
+	}
+	if config.ReposTimeout == 0 {
+		config.ReposTimeout = config.GitHubTimeout // Default value
+	}
+	if config.OrgsTimeout == 0 {
+		config.OrgsTimeout = config.GitHubTimeout // Default value
+	}
+	if config.EmptyIncludeMode == "" {
+		config.EmptyIncludeMode = EmptyIncludeFetch // Default value
+	}
+	if config.EmptyIncludeMode != EmptyIncludeFetch && config.EmptyIncludeMode != EmptyIncludeReject && config.EmptyIncludeMode != EmptyIncludeUsername {
+		return fmt.Errorf("githubstats: invalid EmptyIncludeMode %q", config.EmptyIncludeMode)
+	}
+	if config.RateLimitMode == "" {
+		config.RateLimitMode = RateLimitModeReject // Default value
+	}
+	if config.RateLimitMode != RateLimitModeReject && config.RateLimitMode != RateLimitModeWait {
+		return fmt.Errorf("githubstats: invalid RateLimitMode %q", config.RateLimitMode)
+	}
+	if config.MaxRateLimitWait == 0 {
+		config.MaxRateLimitWait = 10 * time.Second // Default value
+	}
+	if config.TimeFormat == "" {
+		config.TimeFormat = TimeFormatRFC3339 // Default value
+	}
+	if config.TimeFormat != TimeFormatRFC3339 && config.TimeFormat != TimeFormatUnix && config.TimeFormat != TimeFormatUnixMs {
+		return fmt.Errorf("githubstats: invalid TimeFormat %q", config.TimeFormat)
+	}
+	if config.ShutdownTimeout == 0 {
+		config.ShutdownTimeout = 10 * time.Second // Default value
+	}
+	if config.APIVersion == "" {
+		config.APIVersion = DefaultAPIVersion // Default value
+	}
+	if err := validateForcedOptions(config.ForceIncludeOptions, config.Presets); err != nil {
+		return err
+	}
+	trustedProxies, err := parseTrustedProxies(config.TrustedProxies)
+	if err != nil {
+		return err
+	}
+
+	g.config = config
+	g.trustedProxies = trustedProxies
+	if config.RedactUsernamesInLogs {
+		g.redactionKey = processRedactionKey
+		if config.RedactionKey != "" {
+			g.redactionKey = []byte(config.RedactionKey)
+		}
+	}
+	g.staticResponses = indexStaticResponses(config.StaticResponses)
+	g.refreshing = make(map[string]bool)
 
-	ctx := context.Background()
-	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: config.Token},
-	)
-	tc := oauth2.NewClient(ctx, ts)
-	g.client = github.NewClient(tc)
+	g.transport = baseTransport(config)
+	g.clients = newClientPool(sources, config, g.transport)
+	g.client = g.clients.clients[0].client
 
-	g.cache = NewCache()
-	g.rateLimiter = NewRateLimiter(config.RateLimit, 1*time.Minute) // 10 requests per minute
+	switch {
+	case config.Cache != nil:
+		// Shared with other instances, CompressCache and MaxCacheEntrySize are decided by whoever created it
+		g.cache = config.Cache
+	case config.CompressCache:
+		g.cache = NewCompressedCache()
+		g.cache.SetMaxEntrySize(config.MaxCacheEntrySize)
+	default:
+		g.cache = NewCache()
+		g.cache.SetMaxEntrySize(config.MaxCacheEntrySize)
+	}
+	g.rateLimiter = newMinuteRateLimiter(config.RateLimit, config.RateBurst) // 10 requests per minute
+	g.endpointLimiters = make(map[string]*RateLimiter, len(config.EndpointRateLimits))
+	for path, limit := range config.EndpointRateLimits {
+		// A global burst above the endpoint limit would let more requests through at once than it allows per minute
+		g.endpointLimiters[path] = newMinuteRateLimiter(limit, min(config.RateBurst, limit))
+	}
+	g.breaker = NewCircuitBreaker(config.BreakerThreshold, config.BreakerCooldown)
+	g.errorRate = NewErrorRate(config.ErrorRateWindow)
+	if config.AccessLog != nil {
+		g.accessLog = newAccessLogger(config.AccessLog, g.redactionKey, g.clientIP)
+	}
 
-	// Start the HTTP server
-	http.HandleFunc(config.Path, func(w http.ResponseWriter, r *http.Request) {
+	// Each instance has its own routes, so several can run in one process
+	mux := http.NewServeMux()
+	mux.Handle(config.Path, g.wrapHandler(func(w http.ResponseWriter, r *http.Request) {
 		g.githubStatsHandler(w, r, config)
-	})
+	}))
+	mux.Handle(config.ComparePath, g.wrapHandler(g.compareHandler))
+	mux.Handle(config.ReposPath, g.wrapHandler(g.reposHandler))
+	mux.Handle(config.OrgPath, g.wrapHandler(g.orgHandler))
+	mux.Handle(config.HealthPath, g.wrapHandler(g.healthHandler))
+	mux.Handle(config.ReadinessPath, g.wrapHandler(g.readinessHandler))
+	if config.AdminToken != "" {
+		// The admin endpoints are only rate limited when listed in EndpointRateLimits
+		mux.Handle(config.AdminPath+"/cache", g.wrapHandler(g.requireAdmin(g.limitEndpoint(config.AdminPath+"/cache", g.cacheHandler))))
+		mux.Handle(config.AdminPath+"/warm", g.wrapHandler(g.requireAdmin(g.limitEndpoint(config.AdminPath+"/warm", g.warmHandler))))
+		mux.Handle(config.AdminPath+"/diag", g.wrapHandler(g.requireAdmin(g.limitEndpoint(config.AdminPath+"/diag", g.diagHandler))))
+		mux.Handle(config.AdminPath+"/snapshot", g.wrapHandler(g.requireAdmin(g.limitEndpoint(config.AdminPath+"/snapshot", g.snapshotHandler))))
+		mux.Handle(config.AdminPath+"/ratelimit/reset", g.wrapHandler(g.requireAdmin(g.rateLimitResetHandler)))
+	}
+
+	g.mux = mux
+	return nil
+}
+
+// serve Listen and serve the routes of the instance until Shutdown.
+/*
+ * @param config Config - The configuration, with its default values
+ * @return error? - The error
+ */
+func (g *GStats) serve(config Config) error {
+	// Check the certificate before binding, ServeTLS would only fail once listening
+	var tlsConfig *tls.Config
+	if config.Scheme == "https" {
+		cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		if err != nil {
+			return fmt.Errorf("githubstats: failed to load the TLS certificate (CertFile %q, KeyFile %q): %w", config.CertFile, config.KeyFile, err)
+		}
+		tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+	}
+
+	addr := config.IP + ":" + config.Port
+	lc := net.ListenConfig{}
+	if config.ReusePort {
+		if !reusePortSupported {
+			log.Printf("githubstats: SO_REUSEPORT is not supported on this platform, listening without it")
+		}
+		lc.Control = reusePortControl
+	}
+	ln, err := lc.Listen(context.Background(), "tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	server := &http.Server{Addr: addr, Handler: g.mux, TLSConfig: tlsConfig}
+	g.serverMu.Lock()
+	g.server = server
+	g.serverMu.Unlock()
 
 	if config.Scheme == "https" {
-		// Use ListenAndServeTLS for HTTPS
-		return http.ListenAndServeTLS(config.IP+":"+config.Port, config.CertFile, config.KeyFile, nil)
+		// Use ServeTLS for HTTPS
+		err = server.ServeTLS(ln, "", "")
+	} else {
+		// Use Serve for HTTP
+		err = server.Serve(ln)
+	}
+
+	// Stopped by Shutdown, not an error
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Shutdown Stop the server gracefully, closing the connections still active after ShutdownTimeout.
+/*
+ * @return error - The error
+ */
+func (g *GStats) Shutdown() error {
+	g.serverMu.Lock()
+	server := g.server
+	g.serverMu.Unlock()
+	if server == nil {
+		return nil
 	}
 
-	// Use ListenAndServe for HTTP
-	return http.ListenAndServe(config.IP+":"+config.Port, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), g.config.ShutdownTimeout)
+	defer cancel()
+
+	err := server.Shutdown(ctx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		// Some requests didn't drain in time, force them to stop
+		return server.Close()
+	}
+	return err
 }
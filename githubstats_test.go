@@ -0,0 +1,1312 @@
+package githubstats
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// handleOrgPages Register an organization listing of several pages, each one holding a single organization.
+/*
+ * @param f *fakeGitHub - The fake API
+ * @param login string - The user
+ * @param pages int - The number of pages
+ * @return void
+ */
+func handleOrgPages(f *fakeGitHub, login string, pages int) {
+	f.handle("GET /users/"+login+"/orgs", func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		page = max(page, 1)
+		if page < pages {
+			setNextPage(w, r, page+1)
+		}
+		writeJSON(w, http.StatusOK, []map[string]interface{}{{"login": fmt.Sprintf("org-%d", page)}})
+	})
+}
+
+// TestOrganizationsPaginated Check that every page of the organization listing is read.
+func TestOrganizationsPaginated(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	handleOrgPages(f, "octocat", 3)
+	g := newTestGStats(t, f, Config{})
+
+	stats, err := g.GetGitHubStats("octocat", IncludeOptions{IncludeOrgs: true})
+	if err != nil {
+		t.Fatalf("GetGitHubStats: %v", err)
+	}
+	want := []string{"org-1", "org-2", "org-3"}
+	if !reflect.DeepEqual(stats.Organizations, want) {
+		t.Errorf("Organizations = %v, want %v", stats.Organizations, want)
+	}
+}
+
+// TestOrganizationsMaxOrgPages Check that the listing stops at MaxOrgPages.
+func TestOrganizationsMaxOrgPages(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	handleOrgPages(f, "octocat", 5)
+	g := newTestGStats(t, f, Config{MaxOrgPages: 2})
+
+	stats, err := g.GetGitHubStats("octocat", IncludeOptions{IncludeOrgs: true})
+	if err != nil {
+		t.Fatalf("GetGitHubStats: %v", err)
+	}
+	if len(stats.Organizations) != 2 {
+		t.Errorf("Organizations = %v, want 2 organizations", stats.Organizations)
+	}
+	if calls := f.count("GET /users/octocat/orgs"); calls != 2 {
+		t.Errorf("organization listing calls = %d, want 2", calls)
+	}
+}
+
+// TestHandlerTimeout Check that a request stuck on a slow GitHub call is answered 503 within HandlerTimeout.
+func TestHandlerTimeout(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handle("GET /users/octocat", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	})
+	g := newTestGStats(t, f, Config{HandlerTimeout: 50 * time.Millisecond})
+
+	start := time.Now()
+	rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", rec.Code)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("answered after %v, want about 50ms", elapsed)
+	}
+}
+
+// expireAll Expire every entry of the cache, as if their duration had elapsed.
+/*
+ * @param c *Cache - The cache
+ * @param ago time.Duration - How long ago the entries expired
+ * @return void
+ */
+func expireAll(c *Cache, ago time.Duration) {
+	for key, entry := range c.Snapshot() {
+		c.Set(key, entry.Stats, -ago)
+	}
+}
+
+// expireIn Set every entry of the cache to expire after the given time.
+/*
+ * @param c *Cache - The cache
+ * @param in time.Duration - The time left
+ * @return void
+ */
+func expireIn(c *Cache, in time.Duration) {
+	for key, entry := range c.Snapshot() {
+		c.Set(key, entry.Stats, in)
+	}
+}
+
+// handleFlakyUser Register a user route answering 502 once the returned flag is set.
+/*
+ * @param f *fakeGitHub - The fake API
+ * @param login string - The user, with 7 followers
+ * @return *atomic.Bool - Whether the route fails
+ */
+func handleFlakyUser(f *fakeGitHub, login string) *atomic.Bool {
+	failing := &atomic.Bool{}
+	f.handle("GET /users/"+login, func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			writeJSON(w, http.StatusBadGateway, map[string]string{"message": "Bad Gateway"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"login": login, "followers": 7})
+	})
+	return failing
+}
+
+// TestServeStaleOnError Check that an expired entry is served with X-Cache: STALE when GitHub fails.
+func TestServeStaleOnError(t *testing.T) {
+	f := newFakeGitHub(t)
+	failing := handleFlakyUser(f, "octocat")
+	g := newTestGStats(t, f, Config{ServeStaleOnError: true})
+
+	if rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat&include_followers=true", nil)); rec.Code != http.StatusOK {
+		t.Fatalf("first request: status = %d", rec.Code)
+	}
+	expireAll(g.cache, time.Second)
+	failing.Store(true)
+
+	rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat&include_followers=true", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if cache := rec.Header().Get("X-Cache"); cache != "STALE" {
+		t.Errorf("X-Cache = %q, want STALE", cache)
+	}
+	var stats GitHubStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil || stats.Followers != 7 {
+		t.Errorf("body = %s, want the cached stats", rec.Body)
+	}
+}
+
+// TestServeStaleOnErrorDisabled Check that GitHub failures are reported without ServeStaleOnError.
+func TestServeStaleOnErrorDisabled(t *testing.T) {
+	f := newFakeGitHub(t)
+	failing := handleFlakyUser(f, "octocat")
+	g := newTestGStats(t, f, Config{})
+
+	serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat", nil))
+	expireAll(g.cache, time.Second)
+	failing.Store(true)
+
+	rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", rec.Code)
+	}
+}
+
+// TestProfileReadme Check that the profile README is decoded, and empty when the user has none.
+func TestProfileReadme(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	f.handleUser("ghost", nil)
+	f.handleJSON("GET /repos/octocat/octocat/readme", map[string]string{
+		"type":     "file",
+		"encoding": "base64",
+		"content":  base64.StdEncoding.EncodeToString([]byte("# Hi, I'm Octocat")),
+	})
+	g := newTestGStats(t, f, Config{})
+
+	stats, err := g.GetGitHubStats("octocat", IncludeOptions{IncludeProfileReadme: true})
+	if err != nil {
+		t.Fatalf("GetGitHubStats: %v", err)
+	}
+	if stats.ProfileReadme != "# Hi, I'm Octocat" {
+		t.Errorf("ProfileReadme = %q", stats.ProfileReadme)
+	}
+
+	stats, err = g.GetGitHubStats("ghost", IncludeOptions{IncludeProfileReadme: true})
+	if err != nil {
+		t.Fatalf("GetGitHubStats without README: %v", err)
+	}
+	if stats.ProfileReadme != "" {
+		t.Errorf("ProfileReadme = %q, want empty", stats.ProfileReadme)
+	}
+}
+
+// TestBatchStats Check that the batch usernames are deduplicated and bounded by MaxBatchSize.
+func TestBatchStats(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("alice", map[string]interface{}{"followers": 1})
+	f.handleUser("bob", map[string]interface{}{"followers": 2})
+	g := newTestGStats(t, f, Config{MaxBatchSize: 2})
+
+	rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?usernames=alice,bob,ALICE,alice&include_followers=true", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	var results []GitHubStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &results); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(results) != 2 || results[0].Username != "alice" || results[1].Username != "bob" || results[1].Followers != 2 {
+		t.Errorf("results = %+v, want alice then bob", results)
+	}
+	if calls := f.count("GET /users/alice"); calls != 1 {
+		t.Errorf("alice fetched %d times, want 1", calls)
+	}
+
+	rec = serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?usernames=alice,bob,carol", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status over MaxBatchSize = %d, want 400", rec.Code)
+	}
+}
+
+// TestHeavyMinStars Check that only the repositories with enough stars get their contributors.
+func TestHeavyMinStars(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	f.handleJSON("GET /users/octocat/repos", []map[string]interface{}{
+		repoJSON("octocat", "popular", 500),
+		repoJSON("octocat", "tiny", 3),
+	})
+	f.handleJSON("GET /repos/octocat/{repo}/contributors", []map[string]interface{}{{"login": "octocat", "contributions": 10}})
+	g := newTestGStats(t, f, Config{})
+
+	stats, err := g.GetGitHubStats("octocat", IncludeOptions{IncludeRepos: true, IncludeFirstNRepos: AllRepos, IncludeContributors: true, HeavyMinStars: 100})
+	if err != nil {
+		t.Fatalf("GetGitHubStats: %v", err)
+	}
+	if len(stats.Repositories) != 2 {
+		t.Fatalf("Repositories = %+v, want 2", stats.Repositories)
+	}
+	if stats.Repositories[0].Contributors["octocat"] != 10 {
+		t.Errorf("popular contributors = %v, want octocat", stats.Repositories[0].Contributors)
+	}
+	if stats.Repositories[1].Contributors != nil {
+		t.Errorf("tiny contributors = %v, want none", stats.Repositories[1].Contributors)
+	}
+	if calls := f.count("GET /repos/octocat/{repo}/contributors"); calls != 1 {
+		t.Errorf("contributor calls = %d, want 1", calls)
+	}
+}
+
+// TestInvalidOptionCombination Check that a detail option without its section is answered 422 without calling GitHub.
+func TestInvalidOptionCombination(t *testing.T) {
+	f := newFakeGitHub(t)
+	g := newTestGStats(t, f, Config{})
+
+	rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat&include_contributors=true&include_repos=false", nil))
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("status = %d, want 422", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "include_contributors requires include_repos") {
+		t.Errorf("body = %q", rec.Body)
+	}
+	if calls := f.totalCalls(); calls != 0 {
+		t.Errorf("GitHub calls = %d, want 0", calls)
+	}
+}
+
+// TestMaxStaleDuration Check that an entry expired for longer than MaxStaleDuration is not served stale.
+func TestMaxStaleDuration(t *testing.T) {
+	f := newFakeGitHub(t)
+	failing := handleFlakyUser(f, "octocat")
+	g := newTestGStats(t, f, Config{ServeStaleOnError: true, MaxStaleDuration: time.Minute})
+
+	serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat", nil))
+	failing.Store(true)
+
+	expireAll(g.cache, 30*time.Second)
+	if rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat", nil)); rec.Code != http.StatusOK {
+		t.Errorf("status within MaxStaleDuration = %d, want 200", rec.Code)
+	}
+
+	expireAll(g.cache, time.Hour)
+	if rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat", nil)); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status past MaxStaleDuration = %d, want 503", rec.Code)
+	}
+}
+
+// TestBatchConcurrency Check that a batch never fetches more than BatchConcurrency users at once.
+func TestBatchConcurrency(t *testing.T) {
+	f := newFakeGitHub(t)
+	var inFlight, peak atomic.Int32
+	f.handle("GET /users/{user}", func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		writeJSON(w, http.StatusOK, map[string]interface{}{"login": r.PathValue("user")})
+	})
+	g := newTestGStats(t, f, Config{MaxBatchSize: 6, BatchConcurrency: 2})
+
+	rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?usernames=u1,u2,u3,u4,u5,u6", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	if f.count("GET /users/{user}") != 6 {
+		t.Errorf("users fetched = %d, want 6", f.count("GET /users/{user}"))
+	}
+	if p := peak.Load(); p > 2 {
+		t.Errorf("peak concurrency = %d, want at most 2", p)
+	}
+}
+
+// TestBatchConcurrencyInvalid Check that a negative BatchConcurrency is rejected.
+func TestBatchConcurrencyInvalid(t *testing.T) {
+	if _, err := NewGStats(Config{Token: "test-token", BatchConcurrency: -1}); err == nil {
+		t.Error("NewGStats accepted a negative BatchConcurrency")
+	}
+}
+
+// TestFollowersSkipRepositoryListing Check that the profile counts alone don't list the repositories.
+func TestFollowersSkipRepositoryListing(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", map[string]interface{}{"followers": 3, "following": 4})
+	f.handleJSON("GET /users/octocat/repos", []map[string]interface{}{})
+	g := newTestGStats(t, f, Config{})
+
+	stats, err := g.GetGitHubStats("octocat", IncludeOptions{IncludeFollowers: true, IncludeFollowing: true})
+	if err != nil {
+		t.Fatalf("GetGitHubStats: %v", err)
+	}
+	if stats.Followers != 3 || stats.Following != 4 {
+		t.Errorf("Followers, Following = %d, %d, want 3, 4", stats.Followers, stats.Following)
+	}
+	if calls := f.count("GET /users/octocat/repos"); calls != 0 {
+		t.Errorf("repository listing calls = %d, want 0", calls)
+	}
+}
+
+// TestCustomComputers Check that the custom computers set their values and a failing one only adds a warning.
+func TestCustomComputers(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	f.handleJSON("GET /users/octocat/gists", []map[string]interface{}{{"id": "1"}, {"id": "2"}})
+	gists := func(ctx context.Context, client *github.Client, username string, stats *GitHubStats) error {
+		list, _, err := client.Gists.List(ctx, username, nil)
+		if err != nil {
+			return err
+		}
+		stats.Custom = map[string]interface{}{"gists": len(list)}
+		return nil
+	}
+	failing := func(ctx context.Context, client *github.Client, username string, stats *GitHubStats) error {
+		return errors.New("boom")
+	}
+	g := newTestGStats(t, f, Config{CustomComputers: []StatComputer{gists, failing}})
+
+	stats, err := g.GetGitHubStats("octocat", IncludeOptions{})
+	if err != nil {
+		t.Fatalf("GetGitHubStats: %v", err)
+	}
+	if stats.Custom["gists"] != 2 {
+		t.Errorf("Custom = %v, want 2 gists", stats.Custom)
+	}
+	if len(stats.Warnings) != 1 || stats.Warnings[0] != "custom computer 1: boom" {
+		t.Errorf("Warnings = %v", stats.Warnings)
+	}
+}
+
+// TestClient Check that Client returns the client of the first token, and nil before the setup.
+func TestClient(t *testing.T) {
+	f := newFakeGitHub(t)
+	g := newTestGStats(t, f, Config{Token: "token-a", Tokens: []string{"token-b"}})
+
+	if g.Client() != g.clients.clients[0].client {
+		t.Error("Client is not the client of the first token")
+	}
+	if (&GStats{}).Client() != nil {
+		t.Error("Client is not nil before the setup")
+	}
+	var nilStats *GStats
+	if nilStats.Client() != nil {
+		t.Error("Client is not nil on a nil instance")
+	}
+}
+
+// TestTotalRepositories Check that TotalRepositories is the full count of the user payload, not the truncated list.
+func TestTotalRepositories(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", map[string]interface{}{"public_repos": 42})
+	f.handleJSON("GET /users/octocat/repos", []map[string]interface{}{
+		repoJSON("octocat", "a", 1), repoJSON("octocat", "b", 1), repoJSON("octocat", "c", 1),
+	})
+	g := newTestGStats(t, f, Config{})
+
+	stats, err := g.GetGitHubStats("octocat", IncludeOptions{IncludeRepos: true, IncludeFirstNRepos: 2})
+	if err != nil {
+		t.Fatalf("GetGitHubStats: %v", err)
+	}
+	if stats.TotalRepositories != 42 || len(stats.Repositories) != 2 {
+		t.Errorf("TotalRepositories = %d with %d repositories, want 42 with 2", stats.TotalRepositories, len(stats.Repositories))
+	}
+}
+
+// TestNegativeCache Check that a second lookup of an unknown user is answered from the cache.
+func TestNegativeCache(t *testing.T) {
+	f := newFakeGitHub(t)
+	g := newTestGStats(t, f, Config{NegativeCacheDuration: time.Minute})
+
+	for i := 0; i < 2; i++ {
+		rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=ghost", nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("request %d: status = %d, want 404", i, rec.Code)
+		}
+	}
+	if calls := f.totalCalls(); calls != 1 {
+		t.Errorf("GitHub calls = %d, want 1", calls)
+	}
+}
+
+// TestWarmOnStart Check that the WarmOnStart users are cached in the background once Connect runs.
+func TestWarmOnStart(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("alice", nil)
+	f.handleUser("bob", nil)
+	redirectDefaultTransport(t, f)
+
+	g := &GStats{}
+	startServer(t, g, Config{Token: "test-token", WarmOnStart: []string{"alice", "bob"}})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(g.cache.Keys()) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("cache keys = %v, want alice and bob", g.cache.Keys())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if f.count("GET /users/alice") != 1 || f.count("GET /users/bob") != 1 {
+		t.Errorf("user calls = %d, %d, want 1 each", f.count("GET /users/alice"), f.count("GET /users/bob"))
+	}
+}
+
+// TestStats Check that Stats caches like the handler and respects the rate limiter, without serving HTTP.
+func TestStats(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", map[string]interface{}{"followers": 5})
+	g := newTestGStats(t, f, Config{RateLimit: 2})
+	opts := IncludeOptions{IncludeFollowers: true}
+
+	for i := 0; i < 2; i++ {
+		stats, err := g.Stats(context.Background(), "octocat", opts)
+		if err != nil || stats.Followers != 5 {
+			t.Fatalf("call %d: Stats = %+v, %v", i, stats, err)
+		}
+	}
+	if calls := f.count("GET /users/octocat"); calls != 1 {
+		t.Errorf("user calls = %d, want 1 (second call cached)", calls)
+	}
+
+	if _, err := g.Stats(context.Background(), "octocat", opts); !errors.Is(err, ErrRequestLimitExceeded) {
+		t.Errorf("err over the rate limit = %v, want ErrRequestLimitExceeded", err)
+	}
+}
+
+// TestShutdownTimeout Check that Shutdown closes the connections of a hung request after ShutdownTimeout.
+func TestShutdownTimeout(t *testing.T) {
+	f := newFakeGitHub(t)
+	received := make(chan struct{})
+	f.handle("GET /users/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(received)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(10 * time.Second):
+		}
+	})
+	redirectDefaultTransport(t, f)
+
+	g := &GStats{}
+	base := startServer(t, g, Config{Token: "test-token", ShutdownTimeout: 100 * time.Millisecond})
+	// http.DefaultTransport goes to the fake API
+	client := &http.Client{Transport: &http.Transport{}}
+	go client.Get(base + "/stats?username=slow")
+	<-received
+
+	start := time.Now()
+	if err := g.Shutdown(); err != nil {
+		t.Errorf("Shutdown: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Shutdown took %v, want about 100ms", elapsed)
+	}
+}
+
+// TestCacheKeyPrefix Check that the cache keys are namespaced with CacheKeyPrefix and the schema version.
+func TestCacheKeyPrefix(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	g := newTestGStats(t, f, Config{CacheKeyPrefix: "prod:"})
+
+	serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat", nil))
+	keys := g.cache.Keys()
+	if len(keys) != 1 || !strings.HasPrefix(keys[0], "prod:"+cacheSchemaVersion+":octocat:") {
+		t.Errorf("cache keys = %v, want prod:%s:octocat:...", keys, cacheSchemaVersion)
+	}
+}
+
+// TestCacheKeyOptions Check that the stats fetched with some options are never served to a request with other ones.
+func TestCacheKeyOptions(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	f.handleJSON("GET /users/octocat/repos", []map[string]interface{}{repoJSON("octocat", "hello", 3)})
+	g := newTestGStats(t, f, Config{})
+
+	serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat&include_stars=true", nil))
+	rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat&include_repos=true", nil))
+	var stats GitHubStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(stats.Repositories) != 1 {
+		t.Errorf("Repositories = %+v, want the repository of octocat", stats.Repositories)
+	}
+	if keys := g.cache.Keys(); len(keys) != 2 {
+		t.Errorf("cache keys = %v, want one per set of options", keys)
+	}
+
+	// The same options hit the entry
+	serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat&include_repos=true", nil))
+	if calls := f.count("GET /users/octocat/repos"); calls != 2 {
+		t.Errorf("repository list calls = %d, want 2", calls)
+	}
+}
+
+// TestNoDetailCallsWhenOff Check that listing the repositories alone makes no call per repository and needs no budget for them.
+func TestNoDetailCallsWhenOff(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	f.handleJSON("GET /users/octocat/repos", []map[string]interface{}{repoJSON("octocat", "a", 1), repoJSON("octocat", "b", 2)})
+	f.handleJSON("GET /repos/octocat/{repo}/contributors", []map[string]interface{}{})
+	f.handleJSON("GET /repos/octocat/{repo}/languages", map[string]int{})
+	// Just enough for the user and the listing
+	g := newTestGStats(t, f, Config{MaxAPICallsPerRequest: 2})
+
+	stats, err := g.GetGitHubStats("octocat", IncludeOptions{IncludeRepos: true, IncludeFirstNRepos: AllRepos})
+	if err != nil {
+		t.Fatalf("GetGitHubStats: %v", err)
+	}
+	if len(stats.Repositories) != 2 {
+		t.Errorf("Repositories = %+v, want 2", stats.Repositories)
+	}
+	if stats.Partial || stats.BudgetLimited {
+		t.Errorf("Partial = %v, BudgetLimited = %v, want a complete response", stats.Partial, stats.BudgetLimited)
+	}
+	if calls := f.totalCalls(); calls != 2 {
+		t.Errorf("GitHub calls = %d, want the user and the listing only", calls)
+	}
+}
+
+// TestForceIncludeOptions Check that the configured defaults fill the missing parameters and the forced ones win over the query.
+func TestForceIncludeOptions(t *testing.T) {
+	f := newFakeGitHub(t)
+	g := newTestGStats(t, f, Config{
+		IncludeOptions:      IncludeOptions{IncludeStars: true, IncludeRepos: true},
+		ForceIncludeOptions: map[string]string{"include_contributors": "false", "include_followers": "true"},
+	})
+
+	query, _ := url.ParseQuery("include_repos=false&include_contributors=true&include_followers=false")
+	opts, err := g.parseIncludeOptions(query)
+	if err != nil {
+		t.Fatalf("parseIncludeOptions: %v", err)
+	}
+	if !opts.IncludeStars {
+		t.Error("IncludeStars = false, want the configured default")
+	}
+	if opts.IncludeRepos {
+		t.Error("IncludeRepos = true, want the query to override the default")
+	}
+	if opts.IncludeContributors || !opts.IncludeFollowers {
+		t.Errorf("IncludeContributors = %v, IncludeFollowers = %v, want the forced values", opts.IncludeContributors, opts.IncludeFollowers)
+	}
+	if opts.IncludeFirstNRepos != 5 {
+		t.Errorf("IncludeFirstNRepos = %d, want 5", opts.IncludeFirstNRepos)
+	}
+}
+
+// TestEmptyListsSerialized Check that the requested but empty lists are [] and the disabled ones null.
+func TestEmptyListsSerialized(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	f.handleJSON("GET /users/octocat/repos", []interface{}{})
+	f.handleJSON("GET /users/octocat/orgs", []interface{}{})
+	g := newTestGStats(t, f, Config{})
+
+	rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat&include_repos=true&include_orgs=true", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got := string(body["repositories"]); got != "[]" {
+		t.Errorf("repositories = %s, want []", got)
+	}
+	if got := string(body["organizations"]); got != "[]" {
+		t.Errorf("organizations = %s, want []", got)
+	}
+
+	// The cache entry of the first request would be served again
+	g = newTestGStats(t, f, Config{})
+	rec = serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat&include_stars=true", nil))
+	body = nil
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got, ok := body["repositories"]; ok && string(got) != "null" {
+		t.Errorf("repositories = %s without include_repos, want null", got)
+	}
+}
+
+// TestSharedCache Check that a user fetched by one instance is a cache hit for another sharing its cache.
+func TestSharedCache(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", map[string]interface{}{"followers": 3})
+	shared := NewCache()
+	first := newTestGStats(t, f, Config{Cache: shared})
+	second := newTestGStats(t, f, Config{Cache: shared, Path: "/other"})
+
+	if rec := serveRequest(first, httptest.NewRequest(http.MethodGet, "/stats?username=octocat&include_followers=true", nil)); rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	rec := serveRequest(second, httptest.NewRequest(http.MethodGet, "/other?username=octocat&include_followers=true", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	var stats GitHubStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if stats.Followers != 3 {
+		t.Errorf("Followers = %d, want 3", stats.Followers)
+	}
+	if calls := f.count("GET /users/octocat"); calls != 1 {
+		t.Errorf("user calls = %d, want 1", calls)
+	}
+}
+
+// TestConnectMissingCertificate Check that Connect fails before listening when the certificate can't be loaded.
+func TestConnectMissingCertificate(t *testing.T) {
+	dir := t.TempDir()
+	g := &GStats{}
+	err := g.Connect(Config{
+		Token:    "test-token",
+		IP:       "127.0.0.1",
+		Port:     "0",
+		Scheme:   "https",
+		CertFile: dir + "/missing.crt",
+		KeyFile:  dir + "/missing.key",
+	})
+	if err == nil {
+		t.Fatal("Connect: expected an error")
+	}
+	if !strings.Contains(err.Error(), "CertFile") || !strings.Contains(err.Error(), "missing.crt") {
+		t.Errorf("err = %v, want the certificate path", err)
+	}
+}
+
+// TestRepoBranchAndVisibility Check that the default branch and the visibility are mapped from the repository payload.
+func TestRepoBranchAndVisibility(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	repo := repoJSON("octocat", "hello", 1)
+	repo["default_branch"] = "trunk"
+	repo["private"] = true
+	f.handleJSON("GET /users/octocat/repos", []map[string]interface{}{repo})
+	g := newTestGStats(t, f, Config{})
+
+	stats, err := g.GetGitHubStats("octocat", IncludeOptions{IncludeRepos: true, IncludeFirstNRepos: AllRepos})
+	if err != nil {
+		t.Fatalf("GetGitHubStats: %v", err)
+	}
+	if len(stats.Repositories) != 1 {
+		t.Fatalf("Repositories = %+v, want one", stats.Repositories)
+	}
+	if got := stats.Repositories[0]; got.DefaultBranch != "trunk" || !got.Private {
+		t.Errorf("DefaultBranch = %q, Private = %v, want trunk and true", got.DefaultBranch, got.Private)
+	}
+}
+
+// TestReposTimeout Check that the repository listing is bounded by ReposTimeout, defaulting to GitHubTimeout.
+func TestReposTimeout(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	f.handle("GET /users/octocat/repos", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+		writeJSON(w, http.StatusOK, []interface{}{})
+	})
+
+	for name, config := range map[string]Config{
+		"ReposTimeout":  {GitHubTimeout: 5 * time.Second, ReposTimeout: 50 * time.Millisecond},
+		"GitHubTimeout": {GitHubTimeout: 50 * time.Millisecond},
+	} {
+		g := newTestGStats(t, f, config)
+		start := time.Now()
+		_, err := g.GetGitHubStats("octocat", IncludeOptions{IncludeRepos: true})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("%s: err = %v, want a deadline exceeded", name, err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("%s: took %v, want about 50ms", name, elapsed)
+		}
+	}
+}
+
+// TestPushedSince Check that only the repositories pushed after the cutoff are kept, before the truncation.
+func TestPushedSince(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	now := time.Now()
+	var repos []map[string]interface{}
+	for i, age := range []time.Duration{200 * time.Hour, time.Hour, 300 * time.Hour, 2 * time.Hour} {
+		repo := repoJSON("octocat", fmt.Sprint("repo", i), 10-i)
+		repo["pushed_at"] = now.Add(-age).UTC().Format(time.RFC3339)
+		repos = append(repos, repo)
+	}
+	f.handleJSON("GET /users/octocat/repos", repos)
+	g := newTestGStats(t, f, Config{})
+
+	rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat&include_repos=true&include_first_n_repos=2&pushed_since=3d", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	var stats GitHubStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	var names []string
+	for _, repo := range stats.Repositories {
+		names = append(names, repo.Name)
+	}
+	if got := strings.Join(names, ","); got != "repo1,repo3" {
+		t.Errorf("repositories = %s, want repo1,repo3", got)
+	}
+}
+
+// TestPushedSinceInvalid Check that an unparsable pushed_since is answered 400 with the parse error, without any GitHub call.
+func TestPushedSinceInvalid(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	g := newTestGStats(t, f, Config{})
+
+	rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat&include_repos=true&pushed_since=yesterday", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "pushed_since") || !strings.Contains(rec.Body.String(), "yesterday") {
+		t.Errorf("body = %s, want the parse error", rec.Body.String())
+	}
+	if calls := f.totalCalls(); calls != 0 {
+		t.Errorf("GitHub calls = %d, want 0", calls)
+	}
+}
+
+// TestIncludeFirstNRepos Check the AllRepos, NoRepos, default and first N cases, the stars being summed over every repository.
+func TestIncludeFirstNRepos(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	var repos []map[string]interface{}
+	for i := 1; i <= 6; i++ {
+		repos = append(repos, repoJSON("octocat", fmt.Sprint("repo", i), i))
+	}
+	f.handleJSON("GET /users/octocat/repos", repos)
+	g := newTestGStats(t, f, Config{})
+
+	for _, tc := range []struct {
+		firstN int
+		want   int
+	}{
+		{AllRepos, 6},
+		{NoRepos, 0},
+		{0, 5},
+		{2, 2},
+	} {
+		stats, err := g.GetGitHubStats("octocat", IncludeOptions{IncludeRepos: true, IncludeStars: true, IncludeFirstNRepos: tc.firstN})
+		if err != nil {
+			t.Fatalf("GetGitHubStats(%d): %v", tc.firstN, err)
+		}
+		if len(stats.Repositories) != tc.want {
+			t.Errorf("IncludeFirstNRepos %d: %d repositories, want %d", tc.firstN, len(stats.Repositories), tc.want)
+		}
+		if stats.TotalStars != 21 {
+			t.Errorf("IncludeFirstNRepos %d: TotalStars = %d, want 21", tc.firstN, stats.TotalStars)
+		}
+	}
+
+	// The query keeps 0 for none and -1 for all
+	for value, want := range map[string]int{"0": NoRepos, "-1": AllRepos, "3": 3} {
+		opts, err := g.parseIncludeOptions(url.Values{"include_first_n_repos": {value}})
+		if err != nil || opts.IncludeFirstNRepos != want {
+			t.Errorf("include_first_n_repos=%s: IncludeFirstNRepos = %d (%v), want %d", value, opts.IncludeFirstNRepos, err, want)
+		}
+	}
+}
+
+// TestMaxContributorsPerRepo Check that the contributors of a repository are truncated to the top ones.
+func TestMaxContributorsPerRepo(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	f.handleJSON("GET /users/octocat/repos", []map[string]interface{}{repoJSON("octocat", "hello", 1)})
+	var contributors []map[string]interface{}
+	for i, n := range []int{5, 40, 1, 30, 12} {
+		contributors = append(contributors, map[string]interface{}{"login": fmt.Sprint("user", i), "contributions": n})
+	}
+	f.handleJSON("GET /repos/octocat/hello/contributors", contributors)
+	g := newTestGStats(t, f, Config{MaxContributorsPerRepo: 3})
+
+	stats, err := g.GetGitHubStats("octocat", IncludeOptions{IncludeRepos: true, IncludeContributors: true, IncludeFirstNRepos: AllRepos})
+	if err != nil {
+		t.Fatalf("GetGitHubStats: %v", err)
+	}
+	want := map[string]int{"user1": 40, "user3": 30, "user4": 12}
+	if got := stats.Repositories[0].Contributors; !reflect.DeepEqual(got, want) {
+		t.Errorf("Contributors = %v, want %v", got, want)
+	}
+}
+
+// TestTypedErrors Check that the fetch errors can be told apart with errors.Is.
+func TestTypedErrors(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handle("GET /users/limited", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", fmt.Sprint(time.Now().Add(time.Hour).Unix()))
+		writeJSON(w, http.StatusForbidden, map[string]string{"message": "API rate limit exceeded for 127.0.0.1."})
+	})
+	f.handle("GET /users/broken", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusBadGateway, map[string]string{"message": "Bad Gateway"})
+	})
+
+	for username, want := range map[string]error{
+		"ghost":   ErrUserNotFound,
+		"limited": ErrRateLimited,
+		"broken":  ErrGitHubUnavailable,
+	} {
+		// The client remembers an exhausted quota, each case gets its own
+		g := newTestGStats(t, f, Config{})
+		_, err := g.GetGitHubStats(username, IncludeOptions{})
+		if !errors.Is(err, want) {
+			t.Errorf("%s: err = %v, want %v", username, err, want)
+		}
+	}
+}
+
+// TestRefreshAhead Check that the hits near the expiration are served from the cache and trigger a single background refresh.
+func TestRefreshAhead(t *testing.T) {
+	f := newFakeGitHub(t)
+	var calls atomic.Int32
+	release := make(chan struct{})
+	f.handle("GET /users/octocat", func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) > 1 {
+			// Holds the refresh while the other hits come in
+			<-release
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"login": "octocat"})
+	})
+	g := newTestGStats(t, f, Config{CacheDuration: time.Hour, RefreshAheadWindow: time.Minute})
+
+	for i := 0; i < 4; i++ {
+		if rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat", nil)); rec.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d", i, rec.Code)
+		}
+		if i == 0 {
+			expireIn(g.cache, 30*time.Second)
+		}
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for calls.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	close(release)
+	if got := calls.Load(); got != 2 {
+		t.Errorf("GitHub calls = %d, want the first fetch and one refresh", got)
+	}
+
+	for {
+		g.refreshMu.Lock()
+		pending := len(g.refreshing)
+		g.refreshMu.Unlock()
+		if pending == 0 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestRefreshAheadTimeout Check that a hanging background refresh is given up after HandlerTimeout, so the next hit can retry.
+func TestRefreshAheadTimeout(t *testing.T) {
+	f := newFakeGitHub(t)
+	var calls atomic.Int32
+	f.handle("GET /users/octocat", func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) > 1 {
+			<-r.Context().Done()
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"login": "octocat"})
+	})
+	g := newTestGStats(t, f, Config{CacheDuration: time.Hour, RefreshAheadWindow: time.Minute, HandlerTimeout: 50 * time.Millisecond})
+
+	serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat", nil))
+	expireIn(g.cache, 30*time.Second)
+	serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat", nil))
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		g.refreshMu.Lock()
+		pending := len(g.refreshing)
+		g.refreshMu.Unlock()
+		if pending == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the background refresh is still pending past HandlerTimeout")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestRefreshAheadWindowInvalid Check that setup rejects a RefreshAheadWindow refreshing every hit.
+func TestRefreshAheadWindowInvalid(t *testing.T) {
+	if _, err := NewGStats(Config{Token: "test-token", CacheDuration: time.Minute, RefreshAheadWindow: time.Minute}); err == nil {
+		t.Error("NewGStats accepted a RefreshAheadWindow as long as CacheDuration")
+	}
+}
+
+// TestErrorTTLMultiplierInvalid Check that setup rejects an ErrorTTLMultiplier shortening the cache durations.
+func TestErrorTTLMultiplierInvalid(t *testing.T) {
+	for _, multiplier := range []float64{-1, 0.5} {
+		if _, err := NewGStats(Config{Token: "test-token", ErrorTTLMultiplier: multiplier}); err == nil {
+			t.Errorf("NewGStats accepted the ErrorTTLMultiplier %v", multiplier)
+		}
+	}
+	for _, multiplier := range []float64{0, 1, 2.5} {
+		if _, err := NewGStats(Config{Token: "test-token", ErrorTTLMultiplier: multiplier}); err != nil {
+			t.Errorf("NewGStats with the ErrorTTLMultiplier %v: %v", multiplier, err)
+		}
+	}
+}
+
+// TestPresets Check that a preset sets its options, the explicit parameters and the configured presets winning.
+func TestPresets(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	g := newTestGStats(t, f, Config{Presets: map[string]IncludeOptions{"repos": {IncludeRepos: true}}})
+
+	query, _ := url.ParseQuery("preset=minimal&include_followers=false")
+	opts, err := g.parseIncludeOptions(query)
+	if err != nil {
+		t.Fatalf("parseIncludeOptions: %v", err)
+	}
+	if !opts.IncludeStars || opts.IncludeFollowers || opts.IncludeRepos {
+		t.Errorf("minimal preset options = %+v, want the stars only", opts)
+	}
+	query, _ = url.ParseQuery("preset=repos")
+	if opts, _ := g.parseIncludeOptions(query); !opts.IncludeRepos || opts.IncludeStars {
+		t.Errorf("configured preset options = %+v, want the repositories only", opts)
+	}
+
+	if rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat&preset=unknown", nil)); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("status of an unknown preset = %d, want 422", rec.Code)
+	}
+}
+
+// TestResponseBudget Check that the sections left once ResponseBudget is spent are skipped with a warning.
+func TestResponseBudget(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", map[string]interface{}{"followers": 2})
+	f.handle("GET /users/octocat/repos", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		writeJSON(w, http.StatusOK, []map[string]interface{}{repoJSON("octocat", "hello", 1)})
+	})
+	f.handleJSON("GET /users/octocat/orgs", []map[string]interface{}{{"login": "github"}})
+	g := newTestGStats(t, f, Config{ResponseBudget: 50 * time.Millisecond})
+
+	stats, err := g.GetGitHubStats("octocat", IncludeOptions{IncludeFollowers: true, IncludeRepos: true, IncludeOrgs: true, IncludeFirstNRepos: AllRepos})
+	if err != nil {
+		t.Fatalf("GetGitHubStats: %v", err)
+	}
+	if !stats.Partial {
+		t.Error("Partial = false, want true")
+	}
+	if stats.Followers != 2 || len(stats.Repositories) != 1 {
+		t.Errorf("Followers = %d, Repositories = %+v, want the sections fetched in time", stats.Followers, stats.Repositories)
+	}
+	if f.count("GET /users/octocat/orgs") != 0 || len(stats.Warnings) != 1 || !strings.HasPrefix(stats.Warnings[0], "organizations:") {
+		t.Errorf("Warnings = %v, want the organizations skipped", stats.Warnings)
+	}
+}
+
+// TestDeadlineMargin Check that the sections are skipped DeadlineMargin before the request deadline, while the request still has time to answer.
+func TestDeadlineMargin(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", map[string]interface{}{"followers": 2})
+	f.handle("GET /users/octocat/repos", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(150 * time.Millisecond)
+		writeJSON(w, http.StatusOK, []map[string]interface{}{repoJSON("octocat", "hello", 1)})
+	})
+	f.handleJSON("GET /users/octocat/orgs", []map[string]interface{}{{"login": "github"}})
+	g := newTestGStats(t, f, Config{DeadlineMargin: 200 * time.Millisecond})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	stats, err := g.GetGitHubStatsContext(ctx, "octocat", IncludeOptions{IncludeFollowers: true, IncludeRepos: true, IncludeOrgs: true, IncludeFirstNRepos: AllRepos})
+	if err != nil {
+		t.Fatalf("GetGitHubStatsContext: %v", err)
+	}
+	if ctx.Err() != nil {
+		t.Fatal("the request deadline passed, the margin wasn't kept")
+	}
+	if !stats.Partial || stats.Followers != 2 || len(stats.Repositories) != 1 {
+		t.Errorf("Partial = %v, Followers = %d, Repositories = %+v, want the sections fetched in time", stats.Partial, stats.Followers, stats.Repositories)
+	}
+	if f.count("GET /users/octocat/orgs") != 0 {
+		t.Error("the organizations were fetched within the margin")
+	}
+}
+
+// TestDeltas Check that a refresh reports the changes since the value it replaces.
+func TestDeltas(t *testing.T) {
+	f := newFakeGitHub(t)
+	var stars atomic.Int32
+	stars.Store(10)
+	f.handle("GET /users/octocat", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"login": "octocat", "followers": 5 + stars.Load()})
+	})
+	f.handle("GET /users/octocat/repos", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, []map[string]interface{}{repoJSON("octocat", "hello", int(stars.Load())), repoJSON("octocat", "world", 1)})
+	})
+	g := newTestGStats(t, f, Config{})
+	target := "/stats?username=octocat&include_followers=true&include_stars=true&include_repos=true"
+
+	var stats GitHubStats
+	json.Unmarshal(serveRequest(g, httptest.NewRequest(http.MethodGet, target, nil)).Body.Bytes(), &stats)
+	if stats.Deltas != nil {
+		t.Errorf("Deltas = %+v on the first fetch, want none", stats.Deltas)
+	}
+
+	stars.Store(22)
+	expireAll(g.cache, time.Second)
+	stats = GitHubStats{}
+	json.Unmarshal(serveRequest(g, httptest.NewRequest(http.MethodGet, target, nil)).Body.Bytes(), &stats)
+	want := &StatsDeltas{
+		Followers:    12,
+		TotalStars:   12,
+		Repositories: map[string]RepoDelta{"hello": {Stars: 12}, "world": {}},
+	}
+	if !reflect.DeepEqual(stats.Deltas, want) {
+		t.Errorf("Deltas = %+v, want %+v", stats.Deltas, want)
+	}
+}
+
+// TestIncludePrivate Check that the private repositories are only listed with include_private and a token of the user.
+func TestIncludePrivate(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	f.handleJSON("GET /users/octocat/repos", []map[string]interface{}{repoJSON("octocat", "public", 1)})
+	f.handle("GET /user", func(w http.ResponseWriter, r *http.Request) {
+		login := "octocat"
+		if r.Header.Get("Authorization") == "Bearer other-token" {
+			login = "alice"
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"login": login})
+	})
+	f.handle("GET /user/repos", func(w http.ResponseWriter, r *http.Request) {
+		secret := repoJSON("octocat", "secret", 1)
+		secret["private"] = true
+		if r.URL.Query().Get("visibility") != "all" {
+			secret = repoJSON("octocat", "unexpected", 1)
+		}
+		writeJSON(w, http.StatusOK, []map[string]interface{}{repoJSON("octocat", "public", 1), secret})
+	})
+	g := newTestGStats(t, f, Config{AllowUserTokens: true})
+	serverOwned := newTestGStats(t, f, Config{AllowPrivateWithServerToken: true})
+
+	for _, tc := range []struct {
+		name    string
+		g       *GStats
+		token   string
+		private bool
+		want    string
+		warning bool
+	}{
+		{"option off", g, "caller-token", false, "public", false},
+		{"server token", g, "", true, "public", true},
+		{"caller owns the account", g, "caller-token", true, "public,secret", false},
+		{"caller is someone else", g, "other-token", true, "public", true},
+		{"server token allowed", serverOwned, "", true, "public,secret", false},
+	} {
+		ctx := context.Background()
+		if tc.token != "" {
+			ctx = context.WithValue(ctx, userClientKey{}, f.userClient(tc.g, tc.token))
+		}
+		stats, err := tc.g.GetGitHubStatsContext(ctx, "octocat", IncludeOptions{IncludeRepos: true, IncludePrivate: tc.private, IncludeFirstNRepos: AllRepos})
+		if err != nil {
+			t.Fatalf("%s: GetGitHubStats: %v", tc.name, err)
+		}
+		var names []string
+		for _, repo := range stats.Repositories {
+			if repo.Private != (repo.Name == "secret") {
+				t.Errorf("%s: %s Private = %v", tc.name, repo.Name, repo.Private)
+			}
+			names = append(names, repo.Name)
+		}
+		if got := strings.Join(names, ","); got != tc.want {
+			t.Errorf("%s: repositories = %s, want %s", tc.name, got, tc.want)
+		}
+		if warned := len(stats.Warnings) > 0; warned != tc.warning {
+			t.Errorf("%s: Warnings = %v", tc.name, stats.Warnings)
+		}
+	}
+}
+
+// TestDisableCache Check that with DisableCache every request calls GitHub and nothing is stored.
+func TestDisableCache(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	g := newTestGStats(t, f, Config{DisableCache: true})
+
+	for i := 0; i < 2; i++ {
+		if rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat", nil)); rec.Code != http.StatusOK {
+			t.Fatalf("status = %d", rec.Code)
+		}
+	}
+	if calls := f.count("GET /users/octocat"); calls != 2 {
+		t.Errorf("user calls = %d, want 2", calls)
+	}
+	if keys := g.cache.Keys(); len(keys) != 0 {
+		t.Errorf("cache keys = %v, want none", keys)
+	}
+}
+
+// TestOrgAwareFetch Check that the account type is reported and an organization's repositories come from the organization endpoint.
+func TestOrgAwareFetch(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	f.handleUser("acme", map[string]interface{}{"type": AccountTypeOrganization})
+	f.handleJSON("GET /users/{user}/repos", []map[string]interface{}{repoJSON("acme", "via-users", 1)})
+	f.handleJSON("GET /orgs/acme/repos", []map[string]interface{}{repoJSON("acme", "via-orgs", 1)})
+	g := newTestGStats(t, f, Config{OrgAwareFetch: true})
+
+	stats, err := g.GetGitHubStats("octocat", IncludeOptions{})
+	if err != nil {
+		t.Fatalf("GetGitHubStats: %v", err)
+	}
+	if stats.AccountType != AccountTypeUser {
+		t.Errorf("AccountType = %q, want %q", stats.AccountType, AccountTypeUser)
+	}
+
+	stats, err = g.GetGitHubStats("acme", IncludeOptions{IncludeRepos: true, IncludeFirstNRepos: AllRepos})
+	if err != nil {
+		t.Fatalf("GetGitHubStats: %v", err)
+	}
+	if stats.AccountType != AccountTypeOrganization {
+		t.Errorf("AccountType = %q, want %q", stats.AccountType, AccountTypeOrganization)
+	}
+	if len(stats.Repositories) != 1 || stats.Repositories[0].Name != "via-orgs" {
+		t.Errorf("Repositories = %+v, want the organization listing", stats.Repositories)
+	}
+	if calls := f.count("GET /users/{user}/repos"); calls != 0 {
+		t.Errorf("user listing calls = %d, want 0", calls)
+	}
+}
+
+// TestWithCallTimeout Check that the call timeout never extends a tighter parent deadline.
+func TestWithCallTimeout(t *testing.T) {
+	parent, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	parentDeadline, _ := parent.Deadline()
+
+	ctx, cancelCall := withCallTimeout(parent, time.Hour)
+	defer cancelCall()
+	if deadline, ok := ctx.Deadline(); !ok || !deadline.Equal(parentDeadline) {
+		t.Errorf("deadline = %v, want the parent's %v", deadline, parentDeadline)
+	}
+
+	ctx, cancelCall = withCallTimeout(context.Background(), 20*time.Millisecond)
+	defer cancelCall()
+	if deadline, ok := ctx.Deadline(); !ok || time.Until(deadline) > 20*time.Millisecond {
+		t.Errorf("deadline = %v, want within 20ms", deadline)
+	}
+
+	if ctx, _ := withCallTimeout(parent, 0); ctx != parent {
+		t.Error("a zero timeout changed the context")
+	}
+}
+
+// TestParentDeadlineHonored Check that a short request deadline stops a slow GitHub call despite a longer GitHubTimeout.
+func TestParentDeadlineHonored(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handle("GET /users/octocat", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"login": "octocat"})
+	})
+	g := newTestGStats(t, f, Config{GitHubTimeout: time.Minute})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := g.GetGitHubStatsContext(ctx, "octocat", IncludeOptions{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want a deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("took %v, want about 50ms", elapsed)
+	}
+}
+
+// TestConnectInvalidScheme Check that Connect rejects a Scheme other than http or https before binding.
+func TestConnectInvalidScheme(t *testing.T) {
+	// Held so that binding would fail with another error
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	defer ln.Close()
+	port := strconv.Itoa(ln.Addr().(*net.TCPAddr).Port)
+
+	g := &GStats{}
+	err = g.Connect(Config{Token: "test-token", IP: "127.0.0.1", Port: port, Scheme: "htps"})
+	if err == nil || !strings.Contains(err.Error(), `invalid Scheme "htps"`) {
+		t.Errorf("err = %v, want the invalid scheme", err)
+	}
+}
+
+// TestMinContributions Check that the contributors under min_contributions are dropped, after the MaxContributorsPerRepo truncation.
+func TestMinContributions(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	f.handleJSON("GET /users/octocat/repos", []map[string]interface{}{repoJSON("octocat", "hello", 1)})
+	f.handleJSON("GET /repos/octocat/hello/contributors", []map[string]interface{}{
+		{"login": "alice", "contributions": 40},
+		{"login": "bob", "contributions": 12},
+		{"login": "carol", "contributions": 3},
+		{"login": "dave", "contributions": 1},
+	})
+	g := newTestGStats(t, f, Config{MaxContributorsPerRepo: 3})
+
+	rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat&include_repos=true&include_contributors=true&min_contributions=5", nil))
+	var stats GitHubStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	want := map[string]int{"alice": 40, "bob": 12}
+	if len(stats.Repositories) != 1 || !reflect.DeepEqual(stats.Repositories[0].Contributors, want) {
+		t.Errorf("Repositories = %+v, want the contributors %v", stats.Repositories, want)
+	}
+}
+
+// TestErrorTTLMultiplier Check that the stats cached while GitHub recently failed are kept ErrorTTLMultiplier times longer.
+func TestErrorTTLMultiplier(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	f.handleUser("alice", nil)
+	f.handle("GET /users/broken", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusBadGateway, map[string]string{"message": "Bad Gateway"})
+	})
+	g := newTestGStats(t, f, Config{CacheDuration: 10 * time.Minute, ErrorTTLMultiplier: 3})
+	ttl := func(username string) time.Duration {
+		serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username="+username, nil))
+		for key, entry := range g.cache.Snapshot() {
+			if strings.Contains(key, ":"+username+":") {
+				return entry.TTL
+			}
+		}
+		t.Fatalf("%s not cached", username)
+		return 0
+	}
+
+	if got := ttl("octocat"); got > 10*time.Minute || got < 9*time.Minute {
+		t.Errorf("TTL without errors = %v, want about 10m", got)
+	}
+	if _, err := g.GetGitHubStats("broken", IncludeOptions{}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if got := ttl("alice"); got > 30*time.Minute || got < 29*time.Minute {
+		t.Errorf("TTL after an error = %v, want about 30m", got)
+	}
+}
@@ -4,7 +4,12 @@ go 1.23
 
 require (
 	github.com/google/go-github v17.0.0+incompatible
+	github.com/vmihailenco/msgpack/v5 v5.4.1
 	golang.org/x/oauth2 v0.23.0
+	golang.org/x/sys v0.25.0
 )
 
-require github.com/google/go-querystring v1.1.0 // indirect
+require (
+	github.com/google/go-querystring v1.1.0 // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+)
@@ -0,0 +1,55 @@
+package githubstats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/github"
+)
+
+// errGraphQL is wrapped by the errors listed in a GraphQL response, e.g. an unknown login.
+var errGraphQL = errors.New("graphql")
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// queryGraphQL Run a query against the GitHub GraphQL API with the authenticated client.
+/*
+ * @param ctx context.Context - The context
+ * @param client *github.Client - The GitHub client
+ * @param query string - The query
+ * @param variables map[string]interface{} - The query variables
+ * @param out interface{} - The value the data is decoded into
+ * @return error? - The error
+ */
+func queryGraphQL(ctx context.Context, client *github.Client, query string, variables map[string]interface{}, out interface{}) error {
+	req, err := client.NewRequest("POST", "graphql", graphQLRequest{Query: query, Variables: variables})
+	if err != nil {
+		return err
+	}
+
+	var resp graphQLResponse
+	if _, err := client.Do(ctx, req, &resp); err != nil {
+		return err
+	}
+	if len(resp.Errors) > 0 {
+		messages := make([]string, len(resp.Errors))
+		for i, e := range resp.Errors {
+			messages[i] = e.Message
+		}
+		return fmt.Errorf("%w: %s", errGraphQL, strings.Join(messages, "; "))
+	}
+	return json.Unmarshal(resp.Data, out)
+}
@@ -0,0 +1,58 @@
+package githubstats
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+type HealthStatus struct {
+	Status    string   `json:"status"`               // "ok", "degraded" or "unavailable"
+	Error     string   `json:"error,omitempty"`      // Why GitHub is unavailable
+	ErrorRate *float64 `json:"error_rate,omitempty"` // Ratio of failed GitHub calls over ErrorRateWindow, once enough calls were made
+}
+
+// healthHandler Handle the liveness requests, never calling GitHub.
+/*
+ * The status is "degraded" when the recent GitHub error ratio exceeds DegradedErrorRate.
+ *
+ * @param w http.ResponseWriter - The response writer
+ * @param r *http.Request - The request
+ * @return void
+ */
+func (g *GStats) healthHandler(w http.ResponseWriter, r *http.Request) {
+	status := HealthStatus{Status: "ok"}
+	if ratio, ok := g.errorRate.Ratio(); ok {
+		status.ErrorRate = &ratio
+		if g.config.DegradedErrorRate > 0 && ratio > g.config.DegradedErrorRate {
+			status.Status = "degraded"
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if status.Status == "degraded" {
+		w.WriteHeader(g.config.DegradedStatusCode)
+	}
+	json.NewEncoder(w).Encode(status)
+}
+
+// readinessHandler Handle the readiness requests, checking that GitHub is reachable and accepts the token.
+/*
+ * The rate limit endpoint is used as the probe since it doesn't count against the quota.
+ *
+ * @param w http.ResponseWriter - The response writer
+ * @param r *http.Request - The request
+ * @return void
+ */
+func (g *GStats) readinessHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), g.config.ReadinessTimeout)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, _, err := g.clients.pick().RateLimits(ctx); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(HealthStatus{Status: "unavailable", Error: err.Error()})
+		return
+	}
+	json.NewEncoder(w).Encode(HealthStatus{Status: "ok"})
+}
@@ -0,0 +1,110 @@
+package githubstats
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// TestReadiness Check that /readyz answers 503 when the GitHub probe fails, and /healthz never calls GitHub.
+func TestReadiness(t *testing.T) {
+	f := newFakeGitHub(t)
+	var failing atomic.Bool
+	failing.Store(true)
+	f.handle("GET /rate_limit", func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"message": "Bad credentials"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"resources": map[string]interface{}{}})
+	})
+	g := newTestGStats(t, f, Config{})
+
+	rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", rec.Code)
+	}
+	var status HealthStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if status.Status != "unavailable" || status.Error == "" {
+		t.Errorf("status = %+v", status)
+	}
+
+	failing.Store(false)
+	if rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/readyz", nil)); rec.Code != http.StatusOK {
+		t.Errorf("status once GitHub answers = %d, want 200", rec.Code)
+	}
+
+	calls := f.totalCalls()
+	if rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/healthz", nil)); rec.Code != http.StatusOK {
+		t.Errorf("/healthz status = %d, want 200", rec.Code)
+	}
+	if f.totalCalls() != calls {
+		t.Error("/healthz called GitHub")
+	}
+}
+
+// TestHealthDegraded Check that /healthz reports "degraded" once the GitHub error ratio exceeds DegradedErrorRate.
+func TestHealthDegraded(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	f.handle("GET /users/broken", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusBadGateway, map[string]string{"message": "Bad Gateway"})
+	})
+	g := newTestGStats(t, f, Config{DegradedErrorRate: 0.4, DegradedStatusCode: http.StatusServiceUnavailable})
+
+	health := func() (int, HealthStatus) {
+		rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		var status HealthStatus
+		if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return rec.Code, status
+	}
+
+	// Alternated so the circuit breaker never opens
+	for i := 0; i < minErrorRateCalls; i++ {
+		username := "octocat"
+		if i%2 == 1 {
+			username = "broken"
+		}
+		if code, status := health(); code != http.StatusOK || status.Status != "ok" {
+			t.Fatalf("after %d calls: %d %+v, want ok", i, code, status)
+		}
+		g.GetGitHubStats(username, IncludeOptions{})
+	}
+
+	code, status := health()
+	if code != http.StatusServiceUnavailable || status.Status != "degraded" {
+		t.Errorf("health = %d %+v, want 503 degraded", code, status)
+	}
+	if status.ErrorRate == nil || *status.ErrorRate != 0.5 {
+		t.Errorf("ErrorRate = %v, want 0.5", status.ErrorRate)
+	}
+}
+
+// TestHealthPaths Check that the probes are served on HealthPath and ReadinessPath by each instance, through the request middlewares.
+func TestHealthPaths(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleJSON("GET /rate_limit", map[string]interface{}{"resources": map[string]interface{}{}})
+	first := newTestGStats(t, f, Config{HealthPath: "/live", ReadinessPath: "/ready"})
+	second := newTestGStats(t, f, Config{HealthPath: "/live", ReadinessPath: "/ready"})
+
+	for _, g := range []*GStats{first, second} {
+		for _, path := range []string{"/live", "/ready"} {
+			r := httptest.NewRequest(http.MethodGet, path, nil)
+			r.Header.Set("X-Request-ID", "probe-1")
+			rec := serveRequest(g, r)
+			if rec.Code != http.StatusOK {
+				t.Errorf("%s status = %d, want 200", path, rec.Code)
+			}
+			if id := rec.Header().Get("X-Request-ID"); id != "probe-1" {
+				t.Errorf("%s X-Request-ID = %q, want the incoming one", path, id)
+			}
+		}
+	}
+}
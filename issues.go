@@ -0,0 +1,60 @@
+package githubstats
+
+import (
+	"context"
+
+	"github.com/google/go-github/github"
+)
+
+// The search API would cost one call per count out of 30 a minute, the GraphQL API counts them all in one query
+const issueBreakdownQuery = `query($owner: String!, $name: String!) {
+  repository(owner: $owner, name: $name) {
+    openIssues: issues(states: OPEN) { totalCount }
+    closedIssues: issues(states: CLOSED) { totalCount }
+    openPullRequests: pullRequests(states: OPEN) { totalCount }
+    closedPullRequests: pullRequests(states: [CLOSED, MERGED]) { totalCount }
+  }
+}`
+
+type IssueBreakdown struct {
+	OpenIssues         int `json:"open_issues"`
+	ClosedIssues       int `json:"closed_issues"`
+	OpenPullRequests   int `json:"open_pull_requests"`
+	ClosedPullRequests int `json:"closed_pull_requests"`
+}
+
+type totalCount struct {
+	TotalCount int `json:"totalCount"`
+}
+
+type issueBreakdownData struct {
+	Repository struct {
+		OpenIssues         totalCount `json:"openIssues"`
+		ClosedIssues       totalCount `json:"closedIssues"`
+		OpenPullRequests   totalCount `json:"openPullRequests"`
+		ClosedPullRequests totalCount `json:"closedPullRequests"`
+	} `json:"repository"`
+}
+
+// fetchIssueBreakdown Count the open and closed issues and pull requests of a repository with a single GraphQL query.
+/*
+ * Closed pull requests include the merged ones.
+ *
+ * @param ctx context.Context - The context
+ * @param client *github.Client - The GitHub client
+ * @param owner string - The repository owner
+ * @param name string - The repository name
+ * @return *IssueBreakdown, error - The counts, the error
+ */
+func fetchIssueBreakdown(ctx context.Context, client *github.Client, owner string, name string) (*IssueBreakdown, error) {
+	var data issueBreakdownData
+	if err := queryGraphQL(ctx, client, issueBreakdownQuery, map[string]interface{}{"owner": owner, "name": name}, &data); err != nil {
+		return nil, err
+	}
+	return &IssueBreakdown{
+		OpenIssues:         data.Repository.OpenIssues.TotalCount,
+		ClosedIssues:       data.Repository.ClosedIssues.TotalCount,
+		OpenPullRequests:   data.Repository.OpenPullRequests.TotalCount,
+		ClosedPullRequests: data.Repository.ClosedPullRequests.TotalCount,
+	}, nil
+}
@@ -0,0 +1,74 @@
+package githubstats
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// TestIssueBreakdown Check that the breakdown comes from a single GraphQL query, for the first MaxIssueBreakdownRepos repositories.
+func TestIssueBreakdown(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	f.handleJSON("GET /users/octocat/repos", []map[string]interface{}{repoJSON("octocat", "hello", 2), repoJSON("octocat", "world", 1)})
+	f.handle("POST /graphql", func(w http.ResponseWriter, r *http.Request) {
+		var req graphQLRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		if req.Variables["owner"] != "octocat" || req.Variables["name"] != "hello" {
+			t.Errorf("unexpected variables %v", req.Variables)
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"data": map[string]interface{}{
+				"repository": map[string]interface{}{
+					"openIssues":         map[string]int{"totalCount": 4},
+					"closedIssues":       map[string]int{"totalCount": 10},
+					"openPullRequests":   map[string]int{"totalCount": 1},
+					"closedPullRequests": map[string]int{"totalCount": 7},
+				},
+			},
+		})
+	})
+	g := newTestGStats(t, f, Config{MaxIssueBreakdownRepos: 1})
+
+	stats, err := g.GetGitHubStats("octocat", IncludeOptions{IncludeRepos: true, IncludeIssueBreakdown: true, IncludeFirstNRepos: AllRepos})
+	if err != nil {
+		t.Fatalf("GetGitHubStats: %v", err)
+	}
+	want := IssueBreakdown{OpenIssues: 4, ClosedIssues: 10, OpenPullRequests: 1, ClosedPullRequests: 7}
+	if got := stats.Repositories[0].IssueBreakdown; got == nil || *got != want {
+		t.Errorf("IssueBreakdown = %+v, want %+v", got, want)
+	}
+	if got := stats.Repositories[1].IssueBreakdown; got != nil {
+		t.Errorf("IssueBreakdown past MaxIssueBreakdownRepos = %+v, want nil", got)
+	}
+	if calls := f.count("POST /graphql"); calls != 1 {
+		t.Errorf("GraphQL calls = %d, want 1", calls)
+	}
+	if calls := f.count("GET /search/issues"); calls != 0 {
+		t.Errorf("search calls = %d, want 0", calls)
+	}
+}
+
+// TestIssueBreakdownFailed Check that a failed breakdown is left null with a warning instead of failing the stats.
+func TestIssueBreakdownFailed(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	f.handleJSON("GET /users/octocat/repos", []map[string]interface{}{repoJSON("octocat", "hello", 2)})
+	f.handle("POST /graphql", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"errors": []map[string]string{{"message": "Something went wrong"}}})
+	})
+	g := newTestGStats(t, f, Config{})
+
+	stats, err := g.GetGitHubStats("octocat", IncludeOptions{IncludeRepos: true, IncludeIssueBreakdown: true, IncludeFirstNRepos: AllRepos})
+	if err != nil {
+		t.Fatalf("GetGitHubStats: %v", err)
+	}
+	if len(stats.Repositories) != 1 || stats.Repositories[0].IssueBreakdown != nil {
+		t.Errorf("Repositories = %+v, want hello without a breakdown", stats.Repositories)
+	}
+	if !stats.Partial || len(stats.Warnings) != 1 {
+		t.Errorf("Partial = %v, Warnings = %v, want a partial response with a warning", stats.Partial, stats.Warnings)
+	}
+}
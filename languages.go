@@ -0,0 +1,121 @@
+package githubstats
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+type LanguageStat struct {
+	Name       string  `json:"name"`
+	Bytes      int     `json:"bytes"`
+	Percentage float64 `json:"percentage"`
+}
+
+// rankLanguages Sum the language bytes of the repositories and rank them by descending size.
+/*
+ * @param repos []RepoStats - The repositories
+ * @param limit int - The maximum number of languages (unlimited if 0)
+ * @return []LanguageStat - The languages
+ */
+func rankLanguages(repos []RepoStats, limit int) []LanguageStat {
+	totals := make(map[string]int)
+	for _, repo := range repos {
+		for name, bytes := range repo.Languages {
+			totals[name] += bytes
+		}
+	}
+	return rankLanguageBytes(totals, limit)
+}
+
+// rankLanguageBytes Rank the languages by descending size.
+/*
+ * @param totals map[string]int - The bytes by language
+ * @param limit int - The maximum number of languages (unlimited if 0)
+ * @return []LanguageStat - The languages
+ */
+func rankLanguageBytes(totals map[string]int, limit int) []LanguageStat {
+	total := 0
+	for _, bytes := range totals {
+		total += bytes
+	}
+
+	languages := make([]LanguageStat, 0, len(totals))
+	for name, bytes := range totals {
+		language := LanguageStat{Name: name, Bytes: bytes}
+		// Languages reported with 0 bytes only would divide by zero
+		if total > 0 {
+			language.Percentage = float64(bytes) * 100 / float64(total)
+		}
+		languages = append(languages, language)
+	}
+	sort.Slice(languages, func(i, j int) bool {
+		if languages[i].Bytes != languages[j].Bytes {
+			return languages[i].Bytes > languages[j].Bytes
+		}
+		return languages[i].Name < languages[j].Name
+	})
+
+	if limit > 0 && len(languages) > limit {
+		languages = languages[:limit]
+	}
+	return languages
+}
+
+// fetchProfileLanguages Sum the language bytes of every listed repository with enough stars, whatever the repositories returned.
+/*
+ * The languages already fetched for the returned repositories are reused, the others cost a call each, up to maxCalls.
+ *
+ * @param ctx context.Context - The context
+ * @param client *github.Client - The GitHub client
+ * @param repos []*github.Repository - The listed repositories
+ * @param fetched []RepoStats - The returned repositories
+ * @param minStars int - The minimum stars of a summed repository
+ * @param maxCalls int - The maximum number of language calls
+ * @param timeout time.Duration - The timeout of each call (none if 0)
+ * @return map[string]int, error - The bytes by language, the error (ErrCallBudgetExhausted with the bytes summed so far)
+ */
+func fetchProfileLanguages(ctx context.Context, client *github.Client, repos []*github.Repository, fetched []RepoStats, minStars int, maxCalls int, timeout time.Duration) (map[string]int, error) {
+	known := make(map[string]map[string]int, len(fetched))
+	for _, repo := range fetched {
+		if repo.Languages != nil {
+			known[repo.Name] = repo.Languages
+		}
+	}
+
+	totals := make(map[string]int)
+	calls := 0
+	for _, repo := range repos {
+		// Left out like the details of the returned repositories
+		if repo.GetStargazersCount() < minStars {
+			continue
+		}
+		languages, found := known[repo.GetName()]
+		if !found {
+			if calls >= maxCalls {
+				continue
+			}
+			if callBudgetFrom(ctx).exhausted() {
+				return totals, ErrCallBudgetExhausted
+			}
+			calls++
+			callCtx, cancel := withCallTimeout(ctx, timeout)
+			var err error
+			languages, _, err = client.Repositories.ListLanguages(callCtx, repo.GetOwner().GetLogin(), repo.GetName())
+			cancel()
+			if errors.Is(err, ErrCallBudgetExhausted) {
+				return totals, err
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		for name, bytes := range languages {
+			totals[name] += bytes
+		}
+	}
+	return totals, nil
+}
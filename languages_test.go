@@ -0,0 +1,135 @@
+package githubstats
+
+import (
+	"math"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRankLanguages Check that the language bytes are summed, ranked and turned into percentages.
+func TestRankLanguages(t *testing.T) {
+	repos := []RepoStats{
+		{Name: "a", Languages: map[string]int{"Go": 600, "Shell": 100}},
+		{Name: "b", Languages: map[string]int{"Go": 200, "Python": 100}},
+	}
+	want := []LanguageStat{
+		{Name: "Go", Bytes: 800, Percentage: 80},
+		{Name: "Python", Bytes: 100, Percentage: 10},
+	}
+	if got := rankLanguages(repos, 2); !reflect.DeepEqual(got, want) {
+		t.Errorf("rankLanguages = %+v, want %+v", got, want)
+	}
+}
+
+// TestTopLanguages Check the top languages of the returned repositories.
+func TestTopLanguages(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	f.handleJSON("GET /users/octocat/repos", []map[string]interface{}{repoJSON("octocat", "a", 1), repoJSON("octocat", "b", 1)})
+	f.handleJSON("GET /repos/octocat/a/languages", map[string]int{"Go": 300, "C": 100})
+	f.handleJSON("GET /repos/octocat/b/languages", map[string]int{"Go": 100})
+	g := newTestGStats(t, f, Config{MaxTopLanguages: 1})
+
+	stats, err := g.GetGitHubStats("octocat", IncludeOptions{IncludeRepos: true, IncludeFirstNRepos: AllRepos, IncludeLanguages: true})
+	if err != nil {
+		t.Fatalf("GetGitHubStats: %v", err)
+	}
+	if len(stats.TopLanguages) != 1 || stats.TopLanguages[0].Name != "Go" || math.Abs(stats.TopLanguages[0].Percentage-80) > 1e-9 {
+		t.Errorf("TopLanguages = %+v, want Go at 80%%", stats.TopLanguages)
+	}
+}
+
+// TestProfileLanguages Check that the profile languages cover every listed repository, their percentages summing to 100.
+func TestProfileLanguages(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	f.handleJSON("GET /users/octocat/repos", []map[string]interface{}{repoJSON("octocat", "a", 1), repoJSON("octocat", "b", 1), repoJSON("octocat", "c", 1)})
+	f.handleJSON("GET /repos/octocat/a/languages", map[string]int{"Go": 500, "C": 100})
+	f.handleJSON("GET /repos/octocat/b/languages", map[string]int{"Go": 100, "Rust": 300})
+	f.handleJSON("GET /repos/octocat/c/languages", map[string]int{})
+	g := newTestGStats(t, f, Config{})
+
+	// Only the first repository is returned
+	stats, err := g.GetGitHubStats("octocat", IncludeOptions{IncludeRepos: true, IncludeFirstNRepos: 1, IncludeLanguages: true, IncludeProfileLanguages: true})
+	if err != nil {
+		t.Fatalf("GetGitHubStats: %v", err)
+	}
+	if len(stats.ProfileLanguages) != 3 || stats.ProfileLanguages[0].Name != "Go" || stats.ProfileLanguages[0].Bytes != 600 {
+		t.Fatalf("ProfileLanguages = %+v, want Go first with 600 bytes of 3 languages", stats.ProfileLanguages)
+	}
+	sum := 0.0
+	for _, language := range stats.ProfileLanguages {
+		sum += language.Percentage
+	}
+	if math.Abs(sum-100) > 1e-6 {
+		t.Errorf("percentages sum to %v, want 100", sum)
+	}
+	if f.count("GET /repos/octocat/a/languages") != 1 {
+		t.Error("the languages of the returned repository were fetched twice")
+	}
+}
+
+// TestProfileLanguagesBounded Check that the profile languages leave out the repositories below heavy_min_stars and past MaxLanguageRepos.
+func TestProfileLanguagesBounded(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	f.handleJSON("GET /users/octocat/repos", []map[string]interface{}{
+		repoJSON("octocat", "a", 10), repoJSON("octocat", "tiny", 1), repoJSON("octocat", "b", 10), repoJSON("octocat", "c", 10),
+	})
+	f.handleJSON("GET /repos/octocat/{repo}/languages", map[string]int{"Go": 100})
+	g := newTestGStats(t, f, Config{MaxLanguageRepos: 1})
+
+	// a is returned, only b is fetched on top of it
+	stats, err := g.GetGitHubStats("octocat", IncludeOptions{IncludeRepos: true, IncludeFirstNRepos: 1, IncludeLanguages: true, IncludeProfileLanguages: true, HeavyMinStars: 5})
+	if err != nil {
+		t.Fatalf("GetGitHubStats: %v", err)
+	}
+	if calls := f.count("GET /repos/octocat/{repo}/languages"); calls != 2 {
+		t.Errorf("language calls = %d, want 2", calls)
+	}
+	if len(stats.ProfileLanguages) != 1 || stats.ProfileLanguages[0].Bytes != 200 {
+		t.Errorf("ProfileLanguages = %+v, want Go with 200 bytes", stats.ProfileLanguages)
+	}
+}
+
+// TestProfileLanguagesOption Check that the profile languages cost no call without include_profile_languages, and are skipped once ResponseBudget is spent.
+func TestProfileLanguagesOption(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	f.handle("GET /users/octocat/repos", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		writeJSON(w, http.StatusOK, []map[string]interface{}{repoJSON("octocat", "a", 1), repoJSON("octocat", "b", 1)})
+	})
+	f.handleJSON("GET /repos/octocat/{repo}/languages", map[string]int{"Go": 100})
+	g := newTestGStats(t, f, Config{})
+
+	stats, err := g.GetGitHubStats("octocat", IncludeOptions{IncludeRepos: true, IncludeFirstNRepos: 1, IncludeLanguages: true})
+	if err != nil {
+		t.Fatalf("GetGitHubStats: %v", err)
+	}
+	if calls := f.count("GET /repos/octocat/{repo}/languages"); calls != 1 || stats.ProfileLanguages != nil {
+		t.Errorf("language calls = %d, ProfileLanguages = %+v, want the returned repository only", calls, stats.ProfileLanguages)
+	}
+
+	budgeted := newTestGStats(t, f, Config{ResponseBudget: 50 * time.Millisecond})
+	stats, err = budgeted.GetGitHubStats("octocat", IncludeOptions{IncludeRepos: true, IncludeFirstNRepos: NoRepos, IncludeProfileLanguages: true})
+	if err != nil {
+		t.Fatalf("GetGitHubStats: %v", err)
+	}
+	if !stats.Partial || stats.ProfileLanguages != nil || len(stats.Warnings) != 1 || !strings.HasPrefix(stats.Warnings[0], "profile_languages:") {
+		t.Errorf("Partial = %v, ProfileLanguages = %+v, Warnings = %v, want profile_languages skipped", stats.Partial, stats.ProfileLanguages, stats.Warnings)
+	}
+	if calls := f.count("GET /repos/octocat/{repo}/languages"); calls != 1 {
+		t.Errorf("language calls = %d, want none past the budget", calls)
+	}
+}
+
+// TestProfileLanguagesEmpty Check that repositories without any language give an empty breakdown.
+func TestProfileLanguagesEmpty(t *testing.T) {
+	if got := rankLanguageBytes(map[string]int{}, 0); len(got) != 0 {
+		t.Errorf("rankLanguageBytes = %+v, want none", got)
+	}
+}
@@ -0,0 +1,127 @@
+package githubstats
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// wantsMsgpack Check if the client asked for MessagePack with the Accept header.
+/*
+ * @param r *http.Request - The request
+ * @return bool - The result
+ */
+func wantsMsgpack(r *http.Request) bool {
+	accept := r.Header.Get("Accept")
+	return strings.Contains(accept, "application/msgpack") || strings.Contains(accept, "application/x-msgpack")
+}
+
+// writeData Write a response body as JSON, or as MessagePack when the client asks for it.
+/*
+ * The MessagePack keys are the JSON ones.
+ *
+ * @param w http.ResponseWriter - The response writer
+ * @param r *http.Request - The request
+ * @param name string - The attachment name without extension, used with the download query parameter
+ * @param v interface{} - The value
+ * @return void
+ */
+func writeData(w http.ResponseWriter, r *http.Request, name string, v interface{}) {
+	if !wantsMsgpack(r) {
+		w.Header().Set("Content-Type", "application/json")
+		setDownload(w, r, name, "json")
+		json.NewEncoder(w).Encode(v)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/msgpack")
+	setDownload(w, r, name, "msgpack")
+	enc := msgpack.NewEncoder(w)
+	enc.SetCustomStructTag("json")
+	enc.UseCompactInts(true)
+	enc.Encode(v)
+}
+
+// EncodeMsgpack Serialize the stats, leaving out the zero fields when OmitZeroFields is set like MarshalJSON.
+/*
+ * @param enc *msgpack.Encoder - The encoder, keeping the options of writeData
+ * @return error? - The error
+ */
+func (s GitHubStats) EncodeMsgpack(enc *msgpack.Encoder) error {
+	type plain GitHubStats
+	if !s.omitZero {
+		return enc.Encode(plain(s))
+	}
+
+	names, values := nonZeroFields(plain(s))
+	if err := enc.EncodeMapLen(len(names)); err != nil {
+		return err
+	}
+	for i, name := range names {
+		if err := enc.EncodeString(name); err != nil {
+			return err
+		}
+		if err := enc.Encode(values[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MarshalMsgpack Serialize the time like MarshalJSON, a zero time is serialized as nil.
+/*
+ * @return []byte, error - The MessagePack, the error
+ */
+func (t JSONTime) MarshalMsgpack() ([]byte, error) {
+	if t.IsZero() {
+		return msgpack.Marshal(nil)
+	}
+	switch t.format {
+	case TimeFormatUnix:
+		return msgpack.Marshal(t.Unix())
+	case TimeFormatUnixMs:
+		return msgpack.Marshal(t.UnixMilli())
+	default:
+		return msgpack.Marshal(t.Time.UTC().Format(time.RFC3339))
+	}
+}
+
+// UnmarshalMsgpack Deserialize the time from any of the supported formats.
+/*
+ * @param data []byte - The MessagePack
+ * @return error? - The error
+ */
+func (t *JSONTime) UnmarshalMsgpack(data []byte) error {
+	// The integers come as int64 or uint64 whatever their compact size
+	v, err := msgpack.NewDecoder(bytes.NewReader(data)).DecodeInterfaceLoose()
+	if err != nil {
+		return err
+	}
+	var n int64
+	switch v := v.(type) {
+	case nil:
+		t.Time = time.Time{}
+		return nil
+	case string:
+		t.Time, err = time.Parse(time.RFC3339, v)
+		return err
+	case int64:
+		n = v
+	case uint64:
+		n = int64(v)
+	default:
+		return fmt.Errorf("unsupported MessagePack time %T", v)
+	}
+	// Same heuristic as UnmarshalJSON
+	if n >= 1e11 {
+		t.Time = time.UnixMilli(n).UTC()
+	} else {
+		t.Time = time.Unix(n, 0).UTC()
+	}
+	return nil
+}
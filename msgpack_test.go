@@ -0,0 +1,75 @@
+package githubstats
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// decodeMsgpack Decode a MessagePack response with the JSON keys.
+/*
+ * @param t *testing.T - The test
+ * @param rec *httptest.ResponseRecorder - The response
+ * @param v interface{} - The value the body is decoded into
+ * @return void
+ */
+func decodeMsgpack(t *testing.T, rec *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if got := rec.Header().Get("Content-Type"); got != "application/msgpack" {
+		t.Fatalf("Content-Type = %q, want application/msgpack", got)
+	}
+	dec := msgpack.NewDecoder(bytes.NewReader(rec.Body.Bytes()))
+	dec.SetCustomStructTag("json")
+	if err := dec.Decode(v); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+}
+
+// TestMsgpackRoundTrip Check that the MessagePack stats decode to the same value as the JSON ones.
+func TestMsgpackRoundTrip(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", map[string]interface{}{"followers": 4})
+	repo := repoJSON("octocat", "hello", 3)
+	repo["pushed_at"] = "2026-10-01T12:00:00Z"
+	f.handleJSON("GET /users/octocat/repos", []map[string]interface{}{repo})
+	f.handleJSON("GET /repos/octocat/hello/languages", map[string]int{"Go": 100})
+	g := newTestGStats(t, f, Config{})
+	target := "/stats?username=octocat&include_followers=true&include_stars=true&include_repos=true&include_languages=true"
+
+	var fromJSON GitHubStats
+	if err := json.Unmarshal(serveRequest(g, httptest.NewRequest(http.MethodGet, target, nil)).Body.Bytes(), &fromJSON); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+	r.Header.Set("Accept", "application/msgpack")
+	var fromMsgpack GitHubStats
+	decodeMsgpack(t, serveRequest(g, r), &fromMsgpack)
+	if !reflect.DeepEqual(fromMsgpack, fromJSON) {
+		t.Errorf("MessagePack stats = %+v, want %+v", fromMsgpack, fromJSON)
+	}
+}
+
+// TestMsgpackAggregate Check that the organization aggregate honors the Accept header and download too.
+func TestMsgpackAggregate(t *testing.T) {
+	f := newFakeGitHub(t)
+	handleOrgMembers(f)
+	g := newTestGStats(t, f, Config{})
+
+	r := httptest.NewRequest(http.MethodGet, "/org/acme/aggregate?download=true", nil)
+	r.Header.Set("Accept", "application/msgpack")
+	rec := serveRequest(g, r)
+	var aggregate OrgAggregate
+	decodeMsgpack(t, rec, &aggregate)
+	if aggregate.Members != 2 || aggregate.Followers != 7 {
+		t.Errorf("aggregate = %+v", aggregate)
+	}
+	if got, want := rec.Header().Get("Content-Disposition"), `attachment; filename="acme-aggregate.msgpack"`; got != want {
+		t.Errorf("Content-Disposition = %q, want %q", got, want)
+	}
+}
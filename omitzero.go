@@ -0,0 +1,80 @@
+package githubstats
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// MarshalJSON Serialize the stats, leaving out the zero fields when OmitZeroFields is set.
+/*
+ * @return []byte, error - The JSON, the error
+ */
+func (s GitHubStats) MarshalJSON() ([]byte, error) {
+	type plain GitHubStats
+	if !s.omitZero {
+		return json.Marshal(plain(s))
+	}
+	return marshalOmitZero(plain(s))
+}
+
+// marshalOmitZero Serialize a struct like encoding/json would, as if every field was tagged omitempty.
+/*
+ * @param v interface{} - The struct
+ * @return []byte, error - The JSON, the error
+ */
+func marshalOmitZero(v interface{}) ([]byte, error) {
+	names, values := nonZeroFields(v)
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, name := range names {
+		data, err := json.Marshal(values[i])
+		if err != nil {
+			return nil, err
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(name)
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(data)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// nonZeroFields List the exported non-zero fields of a struct with their JSON names.
+/*
+ * Nil slices and maps are left out but empty ones are kept, so a requested section without data is
+ * still distinguishable from a disabled one.
+ *
+ * @param v interface{} - The struct
+ * @return []string, []interface{} - The names, the values
+ */
+func nonZeroFields(v interface{}) ([]string, []interface{}) {
+	value := reflect.ValueOf(v)
+	fields := value.Type()
+
+	var names []string
+	var values []interface{}
+	for i := 0; i < fields.NumField(); i++ {
+		field := fields.Field(i)
+		if !field.IsExported() || value.Field(i).IsZero() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names = append(names, name)
+		values = append(values, value.Field(i).Interface())
+	}
+	return names, values
+}
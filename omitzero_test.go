@@ -0,0 +1,65 @@
+package githubstats
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestOmitZeroFields Check that the zero and disabled fields are left out, the requested empty lists kept.
+func TestOmitZeroFields(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", map[string]interface{}{"followers": 3})
+	f.handleJSON("GET /users/octocat/orgs", []map[string]interface{}{})
+	g := newTestGStats(t, f, Config{OmitZeroFields: true})
+
+	rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat&include_followers=true&include_orgs=true", nil))
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(rec.Body.Bytes(), &fields); err != nil {
+		t.Fatalf("decode: %v (body %s)", err, rec.Body)
+	}
+	for _, name := range []string{"following", "total_stars", "repositories", "profile_readme", "sponsors"} {
+		if _, found := fields[name]; found {
+			t.Errorf("%s is in the response %s", name, rec.Body)
+		}
+	}
+	if string(fields["followers"]) != "3" || string(fields["organizations"]) != "[]" {
+		t.Errorf("response = %s, want followers and an empty organizations list", rec.Body)
+	}
+}
+
+// TestOmitZeroFieldsMsgpack Check that the MessagePack responses leave out the same fields as the JSON ones.
+func TestOmitZeroFieldsMsgpack(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", map[string]interface{}{"followers": 3})
+	f.handleJSON("GET /users/octocat/orgs", []map[string]interface{}{})
+	g := newTestGStats(t, f, Config{OmitZeroFields: true})
+
+	r := httptest.NewRequest(http.MethodGet, "/stats?username=octocat&include_followers=true&include_orgs=true", nil)
+	r.Header.Set("Accept", "application/msgpack")
+	var fields map[string]interface{}
+	decodeMsgpack(t, serveRequest(g, r), &fields)
+	for _, name := range []string{"following", "total_stars", "repositories", "profile_readme", "sponsors"} {
+		if _, found := fields[name]; found {
+			t.Errorf("%s is in the response %v", name, fields)
+		}
+	}
+	if orgs, ok := fields["organizations"].([]interface{}); !ok || len(orgs) != 0 || fmt.Sprint(fields["followers"]) != "3" {
+		t.Errorf("response = %v, want followers and an empty organizations list", fields)
+	}
+}
+
+// TestOmitZeroFieldsDisabled Check that every field is serialized by default.
+func TestOmitZeroFieldsDisabled(t *testing.T) {
+	data, err := json.Marshal(GitHubStats{Username: "octocat"})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var fields map[string]json.RawMessage
+	json.Unmarshal(data, &fields)
+	if string(fields["following"]) != "0" || string(fields["repositories"]) != "null" {
+		t.Errorf("Marshal = %s, want the zero fields", data)
+	}
+}
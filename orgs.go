@@ -0,0 +1,122 @@
+package githubstats
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/github"
+)
+
+type OrgInfo struct {
+	Login       string `json:"login"`
+	Role        string `json:"role,omitempty"`        // "admin" or "member", empty if the token can't see the membership
+	AvatarURL   string `json:"avatar_url,omitempty"`  // Set with include_org_details
+	Name        string `json:"name,omitempty"`        // Set with include_org_details, for the first MaxOrgDetails organizations
+	Description string `json:"description,omitempty"` // Set with include_org_details, for the first MaxOrgDetails organizations
+}
+
+// fetchOrgRoles Fetch the membership role of the user in each organization.
+/*
+ * The token needs the read:org scope and to be able to see the memberships, otherwise the role is left empty.
+ *
+ * @param ctx context.Context - The context
+ * @param client *github.Client - The GitHub client
+ * @param username string - The username
+ * @param orgs []string - The organization logins
+ * @return []OrgInfo, error - The organizations, the error
+ */
+func fetchOrgRoles(ctx context.Context, client *github.Client, username string, orgs []string) ([]OrgInfo, error) {
+	details := make([]OrgInfo, 0, len(orgs))
+	for _, org := range orgs {
+		info := OrgInfo{Login: org}
+		membership, _, err := client.Organizations.GetOrgMembership(ctx, username, org)
+		switch {
+		case err == nil:
+			info.Role = membership.GetRole()
+		case !isHiddenMembership(err):
+			return nil, err
+		}
+		details = append(details, info)
+	}
+	return details, nil
+}
+
+// fetchOrgDetails Fetch the profile of the first organizations, the others keep the avatar of the listing.
+/*
+ * @param ctx context.Context - The context
+ * @param client *github.Client - The GitHub client
+ * @param details []OrgInfo - The organizations to fill
+ * @param avatars map[string]string - The avatar URL of each organization from the listing
+ * @param limit int - The maximum number of profiles fetched
+ * @return error? - The error
+ */
+func fetchOrgDetails(ctx context.Context, client *github.Client, details []OrgInfo, avatars map[string]string, limit int) error {
+	for i := range details {
+		details[i].AvatarURL = avatars[details[i].Login]
+		if i >= limit {
+			continue
+		}
+		org, _, err := client.Organizations.Get(ctx, details[i].Login)
+		if err != nil {
+			return err
+		}
+		details[i].Name = org.GetName()
+		details[i].Description = org.GetDescription()
+		if avatar := org.GetAvatarURL(); avatar != "" {
+			details[i].AvatarURL = avatar
+		}
+	}
+	return nil
+}
+
+// isHiddenMembership Check if the error means the membership is not visible to the token.
+/*
+ * @param err error - The error
+ * @return bool - The result
+ */
+func isHiddenMembership(err error) bool {
+	var errResp *github.ErrorResponse
+	if !errors.As(err, &errResp) || errResp.Response == nil {
+		return false
+	}
+	status := errResp.Response.StatusCode
+	return status == http.StatusNotFound || status == http.StatusForbidden
+}
+
+// isMissingScope Check if the error is a 403 caused by the token lacking an OAuth scope.
+/*
+ * @param err error - The error
+ * @param scope string - The scope
+ * @return bool - The result
+ */
+func isMissingScope(err error, scope string) bool {
+	// Rate limits are reported as *github.RateLimitError, so they never match here
+	var errResp *github.ErrorResponse
+	if !errors.As(err, &errResp) || errResp.Response == nil || errResp.Response.StatusCode != http.StatusForbidden {
+		return false
+	}
+
+	accepted := errResp.Response.Header.Get("X-Accepted-OAuth-Scopes")
+	granted := errResp.Response.Header.Get("X-OAuth-Scopes")
+	if hasScope(accepted, scope) && !hasScope(granted, scope) {
+		return true
+	}
+	return strings.Contains(strings.ToLower(errResp.Message), "scope")
+}
+
+// hasScope Check if a comma separated scope header contains the scope.
+/*
+ * @param header string - The header value
+ * @param scope string - The scope
+ * @return bool - The result
+ */
+func hasScope(header string, scope string) bool {
+	for _, s := range strings.Split(header, ",") {
+		if strings.TrimSpace(s) == scope {
+			return true
+		}
+	}
+	return false
+}
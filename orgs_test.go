@@ -0,0 +1,80 @@
+package githubstats
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// TestOrgRoles Check the membership role of each organization, left empty when the token can't see it.
+func TestOrgRoles(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	f.handleJSON("GET /users/octocat/orgs", []map[string]interface{}{{"login": "github"}, {"login": "secret-org"}})
+	f.handleJSON("GET /orgs/github/memberships/octocat", map[string]string{"role": "admin", "state": "active"})
+	f.handle("GET /orgs/secret-org/memberships/octocat", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusForbidden, map[string]string{"message": "Must have admin rights"})
+	})
+	g := newTestGStats(t, f, Config{})
+
+	stats, err := g.GetGitHubStats("octocat", IncludeOptions{IncludeOrgs: true, IncludeOrgRoles: true})
+	if err != nil {
+		t.Fatalf("GetGitHubStats: %v", err)
+	}
+	want := []OrgInfo{{Login: "github", Role: "admin"}, {Login: "secret-org"}}
+	if !reflect.DeepEqual(stats.OrganizationDetails, want) {
+		t.Errorf("OrganizationDetails = %+v, want %+v", stats.OrganizationDetails, want)
+	}
+}
+
+// TestOrgsMissingScope Check that a token without read:org only adds a warning, the other sections still served.
+func TestOrgsMissingScope(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", map[string]interface{}{"followers": 9})
+	f.handle("GET /users/octocat/orgs", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Accepted-OAuth-Scopes", "read:org")
+		w.Header().Set("X-OAuth-Scopes", "repo")
+		writeJSON(w, http.StatusForbidden, map[string]string{"message": "Resource not accessible by integration"})
+	})
+	g := newTestGStats(t, f, Config{})
+
+	stats, err := g.GetGitHubStats("octocat", IncludeOptions{IncludeFollowers: true, IncludeOrgs: true})
+	if err != nil {
+		t.Fatalf("GetGitHubStats: %v", err)
+	}
+	if stats.Followers != 9 || stats.Organizations != nil {
+		t.Errorf("Followers = %d, Organizations = %v, want 9 and none", stats.Followers, stats.Organizations)
+	}
+	want := []string{"organizations: the GitHub token is missing the read:org scope"}
+	if !reflect.DeepEqual(stats.Warnings, want) {
+		t.Errorf("Warnings = %v, want %v", stats.Warnings, want)
+	}
+}
+
+// TestOrgDetails Check that every organization gets its avatar, and only the first MaxOrgDetails their profile.
+func TestOrgDetails(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	f.handleJSON("GET /users/octocat/orgs", []map[string]interface{}{
+		{"login": "github", "avatar_url": "https://avatars.example/github"},
+		{"login": "acme", "avatar_url": "https://avatars.example/acme"},
+	})
+	f.handleJSON("GET /orgs/github", map[string]interface{}{"login": "github", "name": "GitHub", "description": "How people build software"})
+	f.handleJSON("GET /orgs/acme", map[string]interface{}{"login": "acme", "name": "Acme"})
+	g := newTestGStats(t, f, Config{MaxOrgDetails: 1})
+
+	stats, err := g.GetGitHubStats("octocat", IncludeOptions{IncludeOrgs: true, IncludeOrgDetails: true})
+	if err != nil {
+		t.Fatalf("GetGitHubStats: %v", err)
+	}
+	want := []OrgInfo{
+		{Login: "github", AvatarURL: "https://avatars.example/github", Name: "GitHub", Description: "How people build software"},
+		{Login: "acme", AvatarURL: "https://avatars.example/acme"},
+	}
+	if !reflect.DeepEqual(stats.OrganizationDetails, want) {
+		t.Errorf("OrganizationDetails = %+v, want %+v", stats.OrganizationDetails, want)
+	}
+	if calls := f.count("GET /orgs/acme"); calls != 0 {
+		t.Errorf("acme profile calls = %d, want 0", calls)
+	}
+}
@@ -0,0 +1,116 @@
+package githubstats
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestRateLimiterBurst Check that a burst is allowed at once, then throttled until the bucket refills.
+func TestRateLimiterBurst(t *testing.T) {
+	rl := NewRateLimiterWithBurst(10, 100*time.Millisecond, 3)
+	for i := 0; i < 3; i++ {
+		if !rl.Allow() {
+			t.Fatalf("request %d of the burst rejected", i)
+		}
+	}
+	if rl.Allow() {
+		t.Fatal("request over the burst allowed")
+	}
+
+	// 10 per 100ms, a token is back after 10ms
+	time.Sleep(20 * time.Millisecond)
+	if !rl.Allow() {
+		t.Error("request rejected after the refill")
+	}
+}
+
+// TestRateBurstHandler Check that RateBurst lets a burst through before answering 429.
+func TestRateBurstHandler(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	g := newTestGStats(t, f, Config{RateLimit: 1, RateBurst: 2})
+
+	for i, want := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+		rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat", nil))
+		if rec.Code != want {
+			t.Errorf("request %d: status = %d, want %d", i, rec.Code, want)
+		}
+	}
+}
+
+// TestEndpointRateLimits Check that an endpoint with its own limit is throttled apart from /stats, its burst capped to its limit.
+func TestEndpointRateLimits(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("alice", nil)
+	f.handleUser("bob", nil)
+	f.handleJSON("GET /users/alice/repos", []interface{}{})
+	f.handleJSON("GET /users/bob/repos", []interface{}{})
+	g := newTestGStats(t, f, Config{RateLimit: 10, RateBurst: 5, EndpointRateLimits: map[string]int{"/compare": 1}})
+
+	for i, want := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		if rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/compare?a=alice&b=bob", nil)); rec.Code != want {
+			t.Errorf("compare %d: status = %d, want %d", i, rec.Code, want)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		if rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=alice", nil)); rec.Code != http.StatusOK {
+			t.Errorf("stats %d: status = %d, want 200", i, rec.Code)
+		}
+	}
+}
+
+// TestRateLimitWait Check that the wait mode serves a request the reject mode answers 429, up to MaxRateLimitWait.
+func TestRateLimitWait(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+
+	// A token every 100ms
+	for _, tc := range []struct {
+		mode    string
+		maxWait time.Duration
+		want    int
+	}{
+		{RateLimitModeReject, 0, http.StatusTooManyRequests},
+		{RateLimitModeWait, 2 * time.Second, http.StatusOK},
+		{RateLimitModeWait, 10 * time.Millisecond, http.StatusTooManyRequests},
+	} {
+		g := newTestGStats(t, f, Config{RateLimit: 600, RateBurst: 1, RateLimitMode: tc.mode, MaxRateLimitWait: tc.maxWait})
+		serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat", nil))
+		if rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat", nil)); rec.Code != tc.want {
+			t.Errorf("%s mode, %v max wait: status = %d, want %d", tc.mode, tc.maxWait, rec.Code, tc.want)
+		}
+	}
+}
+
+// TestRateLimiterReset Check that Reset allows the requests again, and the admin endpoint resets every limiter.
+func TestRateLimiterReset(t *testing.T) {
+	rl := NewRateLimiter(1, time.Hour)
+	rl.Allow()
+	if rl.Allow() {
+		t.Fatal("request over the limit allowed")
+	}
+	rl.Reset()
+	if !rl.Allow() {
+		t.Error("request rejected after Reset")
+	}
+
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	g := newTestGStats(t, f, Config{RateLimit: 1, AdminToken: "secret"})
+	serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat", nil))
+	if rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat", nil)); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", rec.Code)
+	}
+
+	if rec := serveRequest(g, adminRequest(http.MethodPost, "/admin/ratelimit/reset", "wrong", "")); rec.Code != http.StatusUnauthorized {
+		t.Errorf("reset status with a wrong token = %d, want 401", rec.Code)
+	}
+	if rec := serveRequest(g, adminRequest(http.MethodPost, "/admin/ratelimit/reset", "secret", "")); rec.Code != http.StatusNoContent {
+		t.Fatalf("reset status = %d, want 204", rec.Code)
+	}
+	if rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat", nil)); rec.Code != http.StatusOK {
+		t.Errorf("status after the reset = %d, want 200", rec.Code)
+	}
+}
@@ -0,0 +1,87 @@
+package githubstats
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strings"
+)
+
+// usernameParams Query parameters holding usernames, redacted in the logs.
+var usernameParams = []string{"username", "usernames", "a", "b"}
+
+// processRedactionKey Key of the username hashes without RedactionKey, only stable within the process.
+var processRedactionKey = randomKey()
+
+// randomKey Generate a random 32 bytes key.
+/*
+ * @return []byte - The key
+ */
+func randomKey() []byte {
+	key := make([]byte, 32)
+	// Never fails on the supported platforms
+	rand.Read(key)
+	return key
+}
+
+// redactUsername Replace a username with a stable hash, so log lines can still be correlated.
+/*
+ * The hash is keyed, a plain one could be reversed with a list of the public GitHub logins.
+ *
+ * @param key []byte - The key
+ * @param username string - The username
+ * @return string - The hash
+ */
+func redactUsername(key []byte, username string) string {
+	// GitHub logins are case-insensitive, the same user must always get the same hash
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(strings.ToLower(username)))
+	return "user-" + hex.EncodeToString(mac.Sum(nil)[:6])
+}
+
+// redactError Get the error message with the username redacted, GitHub errors embed it in the URL.
+/*
+ * @param key []byte - The key of the hash
+ * @param err error - The error
+ * @param username string - The username
+ * @return string - The message
+ */
+func redactError(key []byte, err error, username string) string {
+	return strings.ReplaceAll(err.Error(), username, redactUsername(key, username))
+}
+
+// redactRequestURI Get the request URI with the username query parameters redacted.
+/*
+ * @param key []byte - The key of the hashes
+ * @param u *url.URL - The request URL
+ * @return string - The request URI
+ */
+func redactRequestURI(key []byte, u *url.URL) string {
+	query := u.Query()
+	changed := false
+	for _, param := range usernameParams {
+		values, ok := query[param]
+		if !ok {
+			continue
+		}
+		for i, value := range values {
+			names := strings.Split(value, ",")
+			for j, name := range names {
+				if name = strings.TrimSpace(name); name != "" {
+					names[j] = redactUsername(key, name)
+				}
+			}
+			values[i] = strings.Join(names, ",")
+		}
+		changed = true
+	}
+	if !changed {
+		return u.RequestURI()
+	}
+
+	redacted := *u
+	redacted.RawQuery = query.Encode()
+	return redacted.RequestURI()
+}
@@ -0,0 +1,61 @@
+package githubstats
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestRedactAccessLog Check that the usernames of the query string are hashed in the access log.
+func TestRedactAccessLog(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	var out bytes.Buffer
+	g := newTestGStats(t, f, Config{AccessLog: &out, RedactUsernamesInLogs: true})
+
+	serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat&include_stars=false", nil))
+	line := out.String()
+	if strings.Contains(line, "octocat") {
+		t.Errorf("log line = %q, contains the username", line)
+	}
+	if !strings.Contains(line, "username="+redactUsername(g.redactionKey, "octocat")) || !strings.Contains(line, "include_stars=false") {
+		t.Errorf("log line = %q, want the hashed username and the other parameters", line)
+	}
+}
+
+// TestRedactUsername Check that the hash is stable and case-insensitive, and replaces the username in errors.
+func TestRedactUsername(t *testing.T) {
+	key := []byte("secret")
+	if redactUsername(key, "OctoCat") != redactUsername(key, "octocat") {
+		t.Error("the hash depends on the case")
+	}
+	if redactUsername(key, "octocat") == redactUsername(key, "alice") {
+		t.Error("two users share a hash")
+	}
+
+	err := errors.New("GET https://api.github.com/users/octocat: 500")
+	if msg := redactError(key, err, "octocat"); strings.Contains(msg, "octocat") {
+		t.Errorf("redactError = %q, contains the username", msg)
+	}
+}
+
+// TestRedactionKey Check that the hashes depend on RedactionKey, so they can't be looked up without it.
+func TestRedactionKey(t *testing.T) {
+	f := newFakeGitHub(t)
+	first := newTestGStats(t, f, Config{RedactUsernamesInLogs: true, RedactionKey: "shared"})
+	second := newTestGStats(t, f, Config{RedactUsernamesInLogs: true, RedactionKey: "shared"})
+	other := newTestGStats(t, f, Config{RedactUsernamesInLogs: true, RedactionKey: "other"})
+
+	if redactUsername(first.redactionKey, "octocat") != redactUsername(second.redactionKey, "octocat") {
+		t.Error("two instances sharing RedactionKey hash differently")
+	}
+	if redactUsername(first.redactionKey, "octocat") == redactUsername(other.redactionKey, "octocat") {
+		t.Error("the hash doesn't depend on RedactionKey")
+	}
+	if unkeyed := newTestGStats(t, f, Config{RedactUsernamesInLogs: true}); unkeyed.redactionKey == nil {
+		t.Error("no random key without RedactionKey")
+	}
+}
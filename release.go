@@ -0,0 +1,36 @@
+package githubstats
+
+import (
+	"context"
+
+	"github.com/google/go-github/github"
+)
+
+type ReleaseInfo struct {
+	Tag         string   `json:"tag"`
+	Name        string   `json:"name"`
+	PublishedAt JSONTime `json:"published_at"`
+}
+
+// fetchLatestRelease Fetch the latest published release of a repository.
+/*
+ * @param ctx context.Context - The context
+ * @param client *github.Client - The GitHub client
+ * @param owner string - The repository owner
+ * @param repo string - The repository name
+ * @return *ReleaseInfo, error - The release (nil if the repository has none), the error
+ */
+func fetchLatestRelease(ctx context.Context, client *github.Client, owner string, repo string) (*ReleaseInfo, error) {
+	release, _, err := client.Repositories.GetLatestRelease(ctx, owner, repo)
+	if isNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ReleaseInfo{
+		Tag:         release.GetTagName(),
+		Name:        release.GetName(),
+		PublishedAt: JSONTime{Time: release.GetPublishedAt().Time},
+	}, nil
+}
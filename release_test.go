@@ -0,0 +1,35 @@
+package githubstats
+
+import (
+	"testing"
+	"time"
+)
+
+// TestLatestRelease Check that the latest release is set, and nil for a repository without release.
+func TestLatestRelease(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	f.handleJSON("GET /users/octocat/repos", []map[string]interface{}{repoJSON("octocat", "released", 2), repoJSON("octocat", "draft", 1)})
+	f.handleJSON("GET /repos/octocat/released/releases/latest", map[string]interface{}{
+		"tag_name":     "v2.1.0",
+		"name":         "Version 2.1",
+		"published_at": "2026-09-30T08:00:00Z",
+	})
+	g := newTestGStats(t, f, Config{})
+
+	stats, err := g.GetGitHubStats("octocat", IncludeOptions{IncludeRepos: true, IncludeLatestRelease: true, IncludeFirstNRepos: AllRepos})
+	if err != nil {
+		t.Fatalf("GetGitHubStats: %v", err)
+	}
+	release := stats.Repositories[0].LatestRelease
+	if release == nil || release.Tag != "v2.1.0" || release.Name != "Version 2.1" {
+		t.Fatalf("LatestRelease = %+v, want v2.1.0", release)
+	}
+	if want := time.Date(2026, 9, 30, 8, 0, 0, 0, time.UTC); !release.PublishedAt.Equal(want) {
+		t.Errorf("PublishedAt = %v, want %v", release.PublishedAt, want)
+	}
+	// The fake API answers 404 like GitHub for a repository without release
+	if got := stats.Repositories[1].LatestRelease; got != nil {
+		t.Errorf("LatestRelease = %+v, want nil", got)
+	}
+}
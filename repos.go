@@ -0,0 +1,157 @@
+package githubstats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/google/go-github/github"
+)
+
+type ReposRequest struct {
+	Repos []string `json:"repos"` // "owner/name" pairs
+}
+
+type RepoResult struct {
+	RepoStats
+	Error string `json:"error,omitempty"` // Why the repository could not be fetched, only its name is set then
+}
+
+// repoFullNamePattern Owner and name of a repository, as GitHub allows them.
+var repoFullNamePattern = regexp.MustCompile(`^[A-Za-z0-9-]{1,39}/[A-Za-z0-9._-]{1,100}$`)
+
+// reposHandler Handle the POST requests getting the stats of a list of repositories, too long for a query string.
+/*
+ * @param w http.ResponseWriter - The response writer
+ * @param r *http.Request - The request
+ * @return void
+ */
+func (g *GStats) reposHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !g.checkStrictParams(w, r, false, reposParams) {
+		return
+	}
+
+	var req ReposRequest
+	if !g.decodeJSONBody(w, r, &req) {
+		return
+	}
+	if len(req.Repos) == 0 {
+		writeError(w, r, "At least one repository is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.Repos) > g.config.MaxReposPerRequest {
+		writeError(w, r, fmt.Sprintf("Too many repositories (max %d)", g.config.MaxReposPerRequest), http.StatusBadRequest)
+		return
+	}
+	for i, fullName := range req.Repos {
+		if !repoFullNamePattern.MatchString(fullName) || strings.HasSuffix(fullName, "/.") || strings.HasSuffix(fullName, "/..") {
+			writeError(w, r, fmt.Sprintf("Invalid repository at index %d: %q, expected owner/name", i, fullName), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Check the request limit
+	if limiter := g.limiterFor(g.config.ReposPath); !g.admit(r.Context(), limiter) {
+		g.writeRateLimited(w, r, limiter)
+		return
+	}
+
+	r = g.withUserClient(r)
+	repos, errs := g.fetchRepos(r.Context(), req.Repos)
+	repos = formatRepos(repos, g.config.TimeFormat)
+	// A failed repository doesn't fail the others
+	results := make([]RepoResult, len(repos))
+	for i, err := range errs {
+		switch {
+		case isNotFound(err):
+			results[i] = RepoResult{RepoStats: RepoStats{Name: req.Repos[i]}, Error: "Repository not found"}
+		case errors.Is(err, ErrCallBudgetExhausted):
+			results[i] = RepoResult{RepoStats: RepoStats{Name: req.Repos[i]}, Error: ErrCallBudgetExhausted.Error()}
+		case err != nil:
+			results[i] = RepoResult{RepoStats: RepoStats{Name: req.Repos[i]}, Error: err.Error()}
+		default:
+			results[i] = RepoResult{RepoStats: repos[i]}
+		}
+	}
+
+	writeData(w, r, "repos-stats", g.envelope(results))
+}
+
+// fetchRepos Get the stats of several repositories with a bounded worker pool.
+/*
+ * Each call goes through the circuit breaker, and they share a budget of MaxAPICallsPerRequest calls.
+ *
+ * @param ctx context.Context - The context
+ * @param fullNames []string - The "owner/name" pairs
+ * @return []RepoStats, []error - The stats and the classified error of each repository, in the request order
+ */
+func (g *GStats) fetchRepos(ctx context.Context, fullNames []string) ([]RepoStats, []error) {
+	if g.config.MaxAPICallsPerRequest > 0 {
+		ctx = withCallBudget(ctx, g.config.MaxAPICallsPerRequest)
+	}
+	client := g.clientFor(ctx)
+	results := make([]RepoStats, len(fullNames))
+	errs := make([]error, len(fullNames))
+
+	// Each result keeps its request index
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < g.config.BatchConcurrency && worker < len(fullNames); worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				owner, name, _ := strings.Cut(fullNames[i], "/")
+				var repo *github.Repository
+				err := g.guardedCall(ctx, func() error {
+					repoCtx, cancel := withCallTimeout(ctx, g.config.GitHubTimeout)
+					defer cancel()
+					var err error
+					repo, _, err = client.Repositories.Get(repoCtx, owner, name)
+					return err
+				})
+				if err != nil {
+					errs[i] = err
+					continue
+				}
+				results[i] = newRepoStats(repo)
+			}
+		}()
+	}
+	for i := range fullNames {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results, errs
+}
+
+// newRepoStats Convert a GitHub repository, without its contributors, languages and release.
+/*
+ * @param repo *github.Repository - The repository
+ * @return RepoStats - The stats, named owner/name
+ */
+func newRepoStats(repo *github.Repository) RepoStats {
+	return RepoStats{
+		Name:       repo.GetFullName(),
+		Stars:      repo.GetStargazersCount(),
+		Forks:      repo.GetForksCount(),
+		OpenIssues: repo.GetOpenIssuesCount(),
+		CreatedAt:  JSONTime{Time: repo.GetCreatedAt().Time},
+		UpdatedAt:  JSONTime{Time: repo.GetUpdatedAt().Time},
+		PushedAt:   JSONTime{Time: repo.GetPushedAt().Time},
+
+		DefaultBranch: repo.GetDefaultBranch(),
+		Private:       repo.GetPrivate(),
+	}
+}
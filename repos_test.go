@@ -0,0 +1,136 @@
+package githubstats
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestReposEndpoint Check that the posted repositories are fetched in the request order.
+func TestReposEndpoint(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleJSON("GET /repos/golang/go", repoJSON("golang", "go", 120))
+	f.handleJSON("GET /repos/octocat/hello", repoJSON("octocat", "hello", 3))
+	g := newTestGStats(t, f, Config{})
+
+	rec := serveRequest(g, httptest.NewRequest(http.MethodPost, "/repos", strings.NewReader(`{"repos": ["octocat/hello", "golang/go"]}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	var repos []RepoResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &repos); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(repos) != 2 || repos[0].Name != "octocat/hello" || repos[1].Name != "golang/go" || repos[1].Stars != 120 {
+		t.Errorf("repos = %+v", repos)
+	}
+}
+
+// TestReposEndpointInvalid Check that the invalid requests are rejected before calling GitHub.
+func TestReposEndpointInvalid(t *testing.T) {
+	f := newFakeGitHub(t)
+	g := newTestGStats(t, f, Config{MaxReposPerRequest: 2})
+
+	for body, want := range map[string]int{
+		`{"repos": []}`:                    http.StatusBadRequest,
+		`{"repos": ["not-a-repo"]}`:        http.StatusBadRequest,
+		`{"repos": ["octocat/.."]}`:        http.StatusBadRequest,
+		`{"repos": ["a/b", "c/d", "e/f"]}`: http.StatusBadRequest,
+		`not json`:                         http.StatusBadRequest,
+	} {
+		if rec := serveRequest(g, httptest.NewRequest(http.MethodPost, "/repos", strings.NewReader(body))); rec.Code != want {
+			t.Errorf("%s: status = %d, want %d", body, rec.Code, want)
+		}
+	}
+	if rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/repos", nil)); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET status = %d, want 405", rec.Code)
+	}
+	if calls := f.totalCalls(); calls != 0 {
+		t.Errorf("GitHub calls = %d, want 0", calls)
+	}
+}
+
+// postRepos Post a list of repositories and decode the results.
+/*
+ * @param t *testing.T - The test
+ * @param g *GStats - The instance
+ * @param body string - The JSON body
+ * @return []RepoResult - The results
+ */
+func postRepos(t *testing.T, g *GStats, body string) []RepoResult {
+	t.Helper()
+	rec := serveRequest(g, httptest.NewRequest(http.MethodPost, "/repos", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	var results []RepoResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &results); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return results
+}
+
+// TestReposEndpointPartial Check that a missing repository is reported in its entry without failing the others.
+func TestReposEndpointPartial(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleJSON("GET /repos/golang/go", repoJSON("golang", "go", 120))
+	g := newTestGStats(t, f, Config{})
+
+	results := postRepos(t, g, `{"repos": ["octocat/missing", "golang/go"]}`)
+	if len(results) != 2 {
+		t.Fatalf("results = %+v, want 2", results)
+	}
+	if results[0].Name != "octocat/missing" || results[0].Error != "Repository not found" {
+		t.Errorf("missing entry = %+v", results[0])
+	}
+	if results[1].Error != "" || results[1].Stars != 120 {
+		t.Errorf("found entry = %+v", results[1])
+	}
+}
+
+// TestReposEndpointBudget Check that the repositories past MaxAPICallsPerRequest are reported without calling GitHub.
+func TestReposEndpointBudget(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleJSON("GET /repos/octocat/{repo}", repoJSON("octocat", "hello", 1))
+	g := newTestGStats(t, f, Config{MaxAPICallsPerRequest: 2, BatchConcurrency: 1})
+
+	results := postRepos(t, g, `{"repos": ["octocat/a", "octocat/b", "octocat/c"]}`)
+	if calls := f.totalCalls(); calls != 2 {
+		t.Errorf("GitHub calls = %d, want 2", calls)
+	}
+	if len(results) != 3 || results[2].Error != ErrCallBudgetExhausted.Error() {
+		t.Errorf("results = %+v, want the last one cut by the budget", results)
+	}
+}
+
+// TestReposEndpointBreaker Check that the calls are refused while the circuit breaker is open.
+func TestReposEndpointBreaker(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleJSON("GET /repos/golang/go", repoJSON("golang", "go", 120))
+	g := newTestGStats(t, f, Config{BreakerThreshold: 1})
+	g.breaker.Failure()
+
+	results := postRepos(t, g, `{"repos": ["golang/go"]}`)
+	if len(results) != 1 || results[0].Error == "" {
+		t.Errorf("results = %+v, want an error", results)
+	}
+	if calls := f.totalCalls(); calls != 0 {
+		t.Errorf("GitHub calls = %d, want 0", calls)
+	}
+}
+
+// TestReposEndpointRecordsFailures Check that a failing repository call opens the circuit breaker.
+func TestReposEndpointRecordsFailures(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handle("GET /repos/golang/go", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusBadGateway, map[string]string{"message": "Bad Gateway"})
+	})
+	g := newTestGStats(t, f, Config{BreakerThreshold: 1})
+
+	postRepos(t, g, `{"repos": ["golang/go"]}`)
+	if g.breaker.Allow() {
+		t.Error("breaker closed after a failed call")
+	}
+}
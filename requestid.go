@@ -0,0 +1,68 @@
+package githubstats
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+)
+
+type requestIDKey struct{}
+
+// maxRequestIDLength Longest incoming X-Request-ID honored, longer ones are replaced.
+const maxRequestIDLength = 128
+
+// RequestID Get the ID of the request the context belongs to.
+/*
+ * @param ctx context.Context - The request context
+ * @return string - The request ID, empty if the context doesn't come from a request
+ */
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// withRequestID Tag each request with an ID, honoring the incoming X-Request-ID, and echo it in the response.
+/*
+ * @param next http.Handler - The handler
+ * @return http.Handler - The handler
+ */
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set("X-Request-ID", id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+// validRequestID Check if an incoming request ID is safe to reuse in the headers and the logs.
+/*
+ * @param id string - The request ID
+ * @return bool - The result
+ */
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID Generate a random UUID v4.
+/*
+ * @return string - The UUID
+ */
+func newRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40 // Version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,30 @@
+package githubstats
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// TestRequestID Check that a valid incoming X-Request-ID is echoed and a missing or unsafe one replaced by a UUID.
+func TestRequestID(t *testing.T) {
+	f := newFakeGitHub(t)
+	g := newTestGStats(t, f, Config{})
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	r := httptest.NewRequest(http.MethodGet, "/stats", nil)
+	r.Header.Set("X-Request-ID", "abc-123")
+	if id := serveRequest(g, r).Header().Get("X-Request-ID"); id != "abc-123" {
+		t.Errorf("X-Request-ID = %q, want the incoming one", id)
+	}
+
+	for _, incoming := range []string{"", "with space", strings.Repeat("a", maxRequestIDLength+1)} {
+		r := httptest.NewRequest(http.MethodGet, "/stats", nil)
+		r.Header.Set("X-Request-ID", incoming)
+		if id := serveRequest(g, r).Header().Get("X-Request-ID"); !uuid.MatchString(id) {
+			t.Errorf("X-Request-ID for %q = %q, want a generated UUID", incoming, id)
+		}
+	}
+}
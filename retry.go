@@ -0,0 +1,97 @@
+package githubstats
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type retryBudgetKey struct{}
+
+// retryBudget A number of retries shared by every GitHub call of a request.
+type retryBudget struct {
+	mu        sync.Mutex
+	remaining int
+}
+
+// retryTransport Retry the idempotent GitHub calls failing with a network error or a 5xx, within the request retry budget.
+type retryTransport struct {
+	base http.RoundTripper
+}
+
+// withRetryBudget Attach a retry budget to the context.
+/*
+ * @param ctx context.Context - The context
+ * @param retries int - The maximum number of retries
+ * @return context.Context - The context
+ */
+func withRetryBudget(ctx context.Context, retries int) context.Context {
+	return context.WithValue(ctx, retryBudgetKey{}, &retryBudget{remaining: retries})
+}
+
+// take Take one retry from the budget.
+/*
+ * @return bool - Whether a retry was left
+ */
+func (b *retryBudget) take() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.remaining <= 0 {
+		return false
+	}
+	b.remaining--
+	return true
+}
+
+// RoundTrip Execute the request, retrying it while the budget allows.
+/*
+ * @param req *http.Request - The request
+ * @return *http.Response, error - The response, the error
+ */
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	budget, _ := req.Context().Value(retryBudgetKey{}).(*retryBudget)
+	backoff := 100 * time.Millisecond
+
+	for {
+		resp, err := t.base.RoundTrip(req)
+		if budget == nil || !isRetryable(req, resp, err) || !budget.take() {
+			return resp, err
+		}
+
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// isRetryable Check if a failed GitHub call can be retried.
+/*
+ * @param req *http.Request - The request
+ * @param resp *http.Response - The response
+ * @param err error - The error
+ * @return bool - The result
+ */
+func isRetryable(req *http.Request, resp *http.Response, err error) bool {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+	// Never sent, a retry would be rejected the same way
+	if errors.Is(err, ErrCallBudgetExhausted) {
+		return false
+	}
+	if err != nil {
+		return req.Context().Err() == nil
+	}
+	return resp.StatusCode >= http.StatusInternalServerError
+}
@@ -0,0 +1,45 @@
+package githubstats
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+// TestRetryBudgetCapsAttempts Check that the retries of a request stop once MaxRetriesPerRequest is used up.
+func TestRetryBudgetCapsAttempts(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	f.handle("GET /users/octocat/repos", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"message": "Unavailable"})
+	})
+	g := newTestGStats(t, f, Config{MaxRetriesPerRequest: 2})
+
+	if _, err := g.GetGitHubStats("octocat", IncludeOptions{IncludeStars: true}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if calls := f.count("GET /users/octocat/repos"); calls != 3 {
+		t.Errorf("repository listing attempts = %d, want 3", calls)
+	}
+}
+
+// TestRetryRecovers Check that a transient failure is retried and the request succeeds.
+func TestRetryRecovers(t *testing.T) {
+	f := newFakeGitHub(t)
+	var calls atomic.Int32
+	f.handle("GET /users/octocat", func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			writeJSON(w, http.StatusBadGateway, map[string]string{"message": "Bad Gateway"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"login": "octocat"})
+	})
+	g := newTestGStats(t, f, Config{MaxRetriesPerRequest: 1})
+
+	if _, err := g.GetGitHubStats("octocat", IncludeOptions{}); err != nil {
+		t.Fatalf("GetGitHubStats: %v", err)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("user calls = %d, want 2", n)
+	}
+}
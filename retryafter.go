@@ -0,0 +1,54 @@
+package githubstats
+
+import (
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// setRetryAfter Set the Retry-After header in seconds, adding up to MaxRetryAfterJitter so the clients don't retry all at once.
+/*
+ * @param w http.ResponseWriter - The response writer
+ * @param wait time.Duration - The time to wait before retrying
+ * @return void
+ */
+func (g *GStats) setRetryAfter(w http.ResponseWriter, wait time.Duration) {
+	seconds := int64((wait + time.Second - 1) / time.Second)
+	if jitter := int64(g.config.MaxRetryAfterJitter / time.Second); jitter > 0 {
+		seconds += rand.Int63n(jitter + 1)
+	}
+	w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+}
+
+// writeRateLimited Write the 429 of a request rejected by a rate limiter, with a Retry-After.
+/*
+ * @param w http.ResponseWriter - The response writer
+ * @param r *http.Request - The request
+ * @param limiter *RateLimiter - The rate limiter
+ * @return void
+ */
+func (g *GStats) writeRateLimited(w http.ResponseWriter, r *http.Request, limiter *RateLimiter) {
+	g.setRetryAfter(w, limiter.RetryAfter())
+	writeError(w, r, "Request limit exceeded", http.StatusTooManyRequests)
+}
+
+// gitHubRetryAfter Get the time until GitHub accepts calls again after rejecting them for the quota.
+/*
+ * @param err error - The error, wrapping a RateLimitError or an AbuseRateLimitError
+ * @return time.Duration - The time to wait, at least a second
+ */
+func gitHubRetryAfter(err error) time.Duration {
+	wait := time.Duration(0)
+	var rateLimitErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &rateLimitErr) {
+		wait = time.Until(rateLimitErr.Rate.Reset.Time)
+	} else if errors.As(err, &abuseErr) {
+		wait = abuseErr.GetRetryAfter()
+	}
+	return max(wait, time.Second)
+}
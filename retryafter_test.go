@@ -0,0 +1,69 @@
+package githubstats
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// TestRetryAfterJitter Check that the Retry-After is rounded up, with a jitter of up to MaxRetryAfterJitter.
+func TestRetryAfterJitter(t *testing.T) {
+	f := newFakeGitHub(t)
+	exact := newTestGStats(t, f, Config{})
+	rec := httptest.NewRecorder()
+	exact.setRetryAfter(rec, 1500*time.Millisecond)
+	if got := rec.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After without jitter = %s, want 2", got)
+	}
+
+	g := newTestGStats(t, f, Config{MaxRetryAfterJitter: 5 * time.Second})
+	seen := make(map[int]bool)
+	for i := 0; i < 200; i++ {
+		rec := httptest.NewRecorder()
+		g.setRetryAfter(rec, 10*time.Second)
+		seconds, err := strconv.Atoi(rec.Header().Get("Retry-After"))
+		if err != nil || seconds < 10 || seconds > 15 {
+			t.Fatalf("Retry-After = %q, want 10 to 15", rec.Header().Get("Retry-After"))
+		}
+		seen[seconds] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("Retry-After values = %v, want them spread", seen)
+	}
+}
+
+// TestGitHubErrorStatus Check that a GitHub rate limit is answered 429 and a GitHub failure 503, both with a Retry-After.
+func TestGitHubErrorStatus(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handle("GET /users/limited", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", fmt.Sprint(time.Now().Add(time.Minute).Unix()))
+		writeJSON(w, http.StatusForbidden, map[string]string{"message": "API rate limit exceeded for 127.0.0.1."})
+	})
+	f.handle("GET /users/failing", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusBadGateway, map[string]string{"message": "Server Error"})
+	})
+	g := newTestGStats(t, f, Config{})
+
+	rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=limited", nil))
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("rate limited status = %d, want 429", rec.Code)
+	}
+	if seconds, err := strconv.Atoi(rec.Header().Get("Retry-After")); err != nil || seconds < 50 || seconds > 61 {
+		t.Errorf("rate limited Retry-After = %q, want the time until the reset", rec.Header().Get("Retry-After"))
+	}
+
+	// A fresh client, the other one now refuses every call until the reset
+	g = newTestGStats(t, f, Config{})
+	rec = serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=failing", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("unavailable status = %d, want 503", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("unavailable response without a Retry-After")
+	}
+}
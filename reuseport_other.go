@@ -0,0 +1,19 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package githubstats
+
+import "syscall"
+
+// reusePortSupported SO_REUSEPORT is not available on this platform.
+const reusePortSupported = false
+
+// reusePortControl Leave the socket untouched, SO_REUSEPORT is not supported.
+/*
+ * @param network string - The network
+ * @param address string - The address
+ * @param c syscall.RawConn - The raw socket
+ * @return error? - The error
+ */
+func reusePortControl(network, address string, c syscall.RawConn) error {
+	return nil
+}
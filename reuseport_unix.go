@@ -0,0 +1,30 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package githubstats
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// reusePortSupported SO_REUSEPORT is available on this platform.
+const reusePortSupported = true
+
+// reusePortControl Set SO_REUSEPORT on the listening socket so several processes can share the port.
+/*
+ * @param network string - The network
+ * @param address string - The address
+ * @param c syscall.RawConn - The raw socket
+ * @return error? - The error
+ */
+func reusePortControl(network, address string, c syscall.RawConn) error {
+	var sockErr error
+	err := c.Control(func(fd uintptr) {
+		sockErr = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEPORT, 1)
+	})
+	if err != nil {
+		return err
+	}
+	return sockErr
+}
@@ -0,0 +1,31 @@
+//go:build linux
+
+package githubstats
+
+import (
+	"context"
+	"net"
+	"testing"
+)
+
+// TestReusePortTwoListeners Check that two listeners with SO_REUSEPORT can bind the same port.
+func TestReusePortTwoListeners(t *testing.T) {
+	lc := net.ListenConfig{Control: reusePortControl}
+	first, err := lc.Listen(context.Background(), "tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("first Listen: %v", err)
+	}
+	defer first.Close()
+
+	second, err := lc.Listen(context.Background(), "tcp", first.Addr().String())
+	if err != nil {
+		t.Fatalf("second Listen on %s: %v", first.Addr(), err)
+	}
+	second.Close()
+
+	// Without the option the port is taken
+	if ln, err := net.Listen("tcp", first.Addr().String()); err == nil {
+		ln.Close()
+		t.Error("Listen without SO_REUSEPORT succeeded on a taken port")
+	}
+}
@@ -0,0 +1,61 @@
+package githubstats
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// top_by values.
+const (
+	TopByStars = "stars" // Most starred first
+	TopByScore = "score" // Highest composite score first, see ScoreWeights
+)
+
+// recencyHorizon Age after which a push no longer adds to the score.
+const recencyHorizon = 365 * 24 * time.Hour
+
+type ScoreWeights struct {
+	Stars   float64 // Points per star
+	Forks   float64 // Points per fork
+	Recency float64 // Points for a push today, decreasing linearly to 0 for a push a year old
+}
+
+// defaultScoreWeights Weights used when Config.ScoreWeights is left empty.
+var defaultScoreWeights = ScoreWeights{Stars: 1, Forks: 2, Recency: 10}
+
+// repoScore Compute the composite score of a repository.
+/*
+ * @param repo *github.Repository - The repository
+ * @param weights ScoreWeights - The weights
+ * @param now time.Time - The current time
+ * @return float64 - The score
+ */
+func repoScore(repo *github.Repository, weights ScoreWeights, now time.Time) float64 {
+	score := weights.Stars*float64(repo.GetStargazersCount()) + weights.Forks*float64(repo.GetForksCount())
+	if age := now.Sub(repo.GetPushedAt().Time); age < recencyHorizon {
+		score += weights.Recency * (1 - float64(age)/float64(recencyHorizon))
+	}
+	return score
+}
+
+// rankRepos Sort the repositories for top_by, keeping the GitHub order between equals.
+/*
+ * @param repos []*github.Repository - The repositories, sorted in place
+ * @param topBy string - TopByStars or TopByScore
+ * @param weights ScoreWeights - The score weights
+ * @return void
+ */
+func rankRepos(repos []*github.Repository, topBy string, weights ScoreWeights) {
+	now := time.Now()
+	key := func(repo *github.Repository) float64 {
+		if topBy == TopByScore {
+			return repoScore(repo, weights, now)
+		}
+		return float64(repo.GetStargazersCount())
+	}
+	sort.SliceStable(repos, func(i, j int) bool {
+		return key(repos[i]) > key(repos[j])
+	})
+}
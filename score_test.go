@@ -0,0 +1,42 @@
+package githubstats
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRankByScore Check that the composite score lifts a recent and forked repository above the most starred ones.
+func TestRankByScore(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	now := time.Now().UTC()
+	old := now.Add(-2 * recencyHorizon).Format(time.RFC3339)
+	repos := []map[string]interface{}{repoJSON("octocat", "old", 10), repoJSON("octocat", "fresh", 5), repoJSON("octocat", "mid", 8)}
+	repos[0]["pushed_at"], repos[2]["pushed_at"] = old, old
+	repos[1]["pushed_at"], repos[1]["forks_count"] = now.Format(time.RFC3339), 2
+	f.handleJSON("GET /users/octocat/repos", repos)
+
+	for _, tc := range []struct {
+		topBy   string
+		weights ScoreWeights
+		want    string
+	}{
+		{TopByStars, ScoreWeights{}, "old,mid,fresh"},
+		{TopByScore, ScoreWeights{}, "fresh,old,mid"},
+		{TopByScore, ScoreWeights{Stars: 1}, "old,mid,fresh"},
+	} {
+		g := newTestGStats(t, f, Config{ScoreWeights: tc.weights})
+		stats, err := g.GetGitHubStats("octocat", IncludeOptions{IncludeRepos: true, IncludeFirstNRepos: AllRepos, TopBy: tc.topBy})
+		if err != nil {
+			t.Fatalf("GetGitHubStats: %v", err)
+		}
+		var names []string
+		for _, repo := range stats.Repositories {
+			names = append(names, repo.Name)
+		}
+		if got := strings.Join(names, ","); got != tc.want {
+			t.Errorf("top_by %s, weights %+v: %s, want %s", tc.topBy, tc.weights, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package githubstats
+
+import (
+	"context"
+	"time"
+)
+
+type cacheSection struct {
+	enabled func(opts IncludeOptions) bool                // Check if the options request the section
+	opts    func(dst *IncludeOptions, src IncludeOptions) // Copy the options fetching the section
+	merge   func(dst *GitHubStats, src GitHubStats)       // Copy the fields of the section
+}
+
+// cacheSections Sections that SectionCacheDurations can refresh on their own.
+var cacheSections = map[string]cacheSection{
+	"profile": {
+		enabled: func(opts IncludeOptions) bool {
+			return opts.IncludeFollowers || opts.IncludeFollowing
+		},
+		opts: func(dst *IncludeOptions, src IncludeOptions) {
+			dst.IncludeFollowers = src.IncludeFollowers
+			dst.IncludeFollowing = src.IncludeFollowing
+		},
+		merge: func(dst *GitHubStats, src GitHubStats) {
+			dst.Followers = src.Followers
+			dst.Following = src.Following
+			dst.TotalRepositories = src.TotalRepositories
+		},
+	},
+	"stars": {
+		enabled: func(opts IncludeOptions) bool {
+			return opts.IncludeStars
+		},
+		opts: func(dst *IncludeOptions, src IncludeOptions) {
+			dst.IncludeStars = src.IncludeStars
+			// The same listing gives the stars of the returned repositories, without their details
+			if src.IncludeRepos {
+				dst.IncludeRepos = true
+				dst.IncludeFirstNRepos = src.IncludeFirstNRepos
+				dst.PushedSince = src.PushedSince
+				dst.TopBy = src.TopBy
+			}
+		},
+		merge: func(dst *GitHubStats, src GitHubStats) {
+			dst.TotalStars = src.TotalStars
+			if dst.Repositories == nil {
+				return
+			}
+			stars := make(map[string]int, len(src.Repositories))
+			for _, repo := range src.Repositories {
+				stars[repo.Name] = repo.Stars
+			}
+			// The slice is shared with the cache
+			repos := make([]RepoStats, len(dst.Repositories))
+			copy(repos, dst.Repositories)
+			for i := range repos {
+				if n, ok := stars[repos[i].Name]; ok {
+					repos[i].Stars = n
+				}
+			}
+			dst.Repositories = repos
+		},
+	},
+	"repositories": {
+		enabled: func(opts IncludeOptions) bool {
+			return opts.IncludeRepos
+		},
+		opts: func(dst *IncludeOptions, src IncludeOptions) {
+			dst.IncludeRepos = src.IncludeRepos
+			dst.IncludeFirstNRepos = src.IncludeFirstNRepos
+			dst.IncludeContributors = src.IncludeContributors
+			dst.IncludeLanguages = src.IncludeLanguages
+			dst.IncludeProfileLanguages = src.IncludeProfileLanguages
+			dst.IncludeLatestRelease = src.IncludeLatestRelease
+			dst.IncludeCommitActivity = src.IncludeCommitActivity
+			dst.HeavyMinStars = src.HeavyMinStars
+			dst.MinContributions = src.MinContributions
+			dst.IncludeIssueBreakdown = src.IncludeIssueBreakdown
+			dst.PushedSince = src.PushedSince
+			dst.TopBy = src.TopBy
+		},
+		merge: func(dst *GitHubStats, src GitHubStats) {
+			dst.Repositories = src.Repositories
+			dst.TopLanguages = src.TopLanguages
+			dst.ProfileLanguages = src.ProfileLanguages
+		},
+	},
+	"organizations": {
+		enabled: func(opts IncludeOptions) bool {
+			return opts.IncludeOrgs
+		},
+		opts: func(dst *IncludeOptions, src IncludeOptions) {
+			dst.IncludeOrgs = src.IncludeOrgs
+			dst.IncludeOrgRoles = src.IncludeOrgRoles
+			dst.IncludeOrgDetails = src.IncludeOrgDetails
+		},
+		merge: func(dst *GitHubStats, src GitHubStats) {
+			dst.Organizations = src.Organizations
+			dst.OrganizationDetails = src.OrganizationDetails
+		},
+	},
+}
+
+// sectionExpirations Get the expiration of the sections with their own cache duration.
+/*
+ * @param scale float64 - The multiplier of the durations
+ * @return map[string]time.Time - The expiration by section (nil if none)
+ */
+func (g *GStats) sectionExpirations(scale float64) map[string]time.Time {
+	if len(g.config.SectionCacheDurations) == 0 {
+		return nil
+	}
+	now := time.Now()
+	sections := make(map[string]time.Time, len(g.config.SectionCacheDurations))
+	for name, duration := range g.config.SectionCacheDurations {
+		if _, ok := cacheSections[name]; ok {
+			sections[name] = now.Add(time.Duration(float64(duration) * scale))
+		}
+	}
+	return sections
+}
+
+// cacheScale Get the multiplier of the cache durations, ErrorTTLMultiplier while GitHub recently failed.
+/*
+ * @return float64 - The multiplier
+ */
+func (g *GStats) cacheScale() float64 {
+	if g.config.ErrorTTLMultiplier > 0 && g.errorRate.Failed() {
+		return g.config.ErrorTTLMultiplier
+	}
+	return 1
+}
+
+// setCached Cache the stats, with the expiration of their sections.
+/*
+ * While GitHub recently failed, the durations are multiplied by ErrorTTLMultiplier to spare it during the recovery.
+ *
+ * @param key string - The cache key
+ * @param stats GitHubStats - The stats
+ * @return void
+ */
+func (g *GStats) setCached(key string, stats GitHubStats) {
+	scale := g.cacheScale()
+	g.cache.SetSections(key, stats, time.Duration(float64(g.config.CacheDuration)*scale), g.sectionExpirations(scale))
+}
+
+// refreshSections Refresh the expired sections of a cached entry with a single fetch limited to them.
+/*
+ * On failure the cached sections are served until the entry itself expires.
+ *
+ * @param ctx context.Context - The context
+ * @param key string - The cache key
+ * @param username string - The username
+ * @param opts IncludeOptions - The options of the request
+ * @param entry CacheEntry - The cached entry
+ * @return GitHubStats - The stats
+ */
+func (g *GStats) refreshSections(ctx context.Context, key string, username string, opts IncludeOptions, entry CacheEntry) GitHubStats {
+	now := time.Now()
+	var expired []string
+	refreshOpts := IncludeOptions{}
+	for name, expiration := range entry.Sections {
+		// A section the request doesn't ask for would be fetched empty and overwrite the cached one
+		if section, ok := cacheSections[name]; ok && now.After(expiration) && section.enabled(opts) {
+			section.opts(&refreshOpts, opts)
+			expired = append(expired, name)
+		}
+	}
+	if len(expired) == 0 {
+		return entry.Stats
+	}
+
+	fresh, err := g.GetGitHubStatsContext(ctx, username, refreshOpts)
+	if err != nil || fresh.Partial {
+		return entry.Stats
+	}
+
+	// The map of the entry is shared with the cache
+	stats := entry.Stats
+	sections := make(map[string]time.Time, len(entry.Sections))
+	for name, expiration := range entry.Sections {
+		sections[name] = expiration
+	}
+	// Scaled like the sections of a new entry
+	refreshed := g.sectionExpirations(g.cacheScale())
+	for _, name := range expired {
+		cacheSections[name].merge(&stats, fresh)
+		sections[name] = refreshed[name]
+	}
+	g.cache.SetSections(key, stats, time.Until(entry.Expiration), sections)
+	return stats
+}
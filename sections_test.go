@@ -0,0 +1,166 @@
+package githubstats
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestSectionCacheDurations Check that an expired section is refreshed on its own, the other sections staying cached.
+func TestSectionCacheDurations(t *testing.T) {
+	f := newFakeGitHub(t)
+	var version atomic.Int32
+	version.Store(1)
+	f.handle("GET /users/octocat", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"login": "octocat", "followers": version.Load()})
+	})
+	f.handle("GET /users/octocat/repos", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, []map[string]interface{}{repoJSON("octocat", "hello", int(10*version.Load()))})
+	})
+	f.handleJSON("GET /users/octocat/orgs", []map[string]interface{}{{"login": "github"}})
+	g := newTestGStats(t, f, Config{
+		CacheDuration:         time.Hour,
+		SectionCacheDurations: map[string]time.Duration{"stars": 10 * time.Millisecond, "organizations": 10 * time.Millisecond},
+	})
+	get := func() GitHubStats {
+		rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat&include_followers=true&include_stars=true", nil))
+		var stats GitHubStats
+		if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return stats
+	}
+
+	if stats := get(); stats.Followers != 1 || stats.TotalStars != 10 {
+		t.Fatalf("first stats = %+v", stats)
+	}
+	version.Store(2)
+	time.Sleep(20 * time.Millisecond)
+
+	stats := get()
+	if stats.TotalStars != 20 {
+		t.Errorf("TotalStars = %d, want the refreshed 20", stats.TotalStars)
+	}
+	if stats.Followers != 1 {
+		t.Errorf("Followers = %d, want the cached 1", stats.Followers)
+	}
+	if calls := f.count("GET /users/octocat/orgs"); calls != 0 {
+		t.Errorf("organization calls = %d, want 0", calls)
+	}
+}
+
+// TestSectionCacheDurationsNotRequested Check that an expired section the request doesn't ask for triggers no refresh.
+func TestSectionCacheDurationsNotRequested(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", map[string]interface{}{"followers": 1})
+	g := newTestGStats(t, f, Config{
+		CacheDuration:         time.Hour,
+		SectionCacheDurations: map[string]time.Duration{"organizations": 10 * time.Millisecond},
+	})
+	target := "/stats?username=octocat&include_followers=true"
+
+	serveRequest(g, httptest.NewRequest(http.MethodGet, target, nil))
+	time.Sleep(20 * time.Millisecond)
+	var stats GitHubStats
+	if err := json.Unmarshal(serveRequest(g, httptest.NewRequest(http.MethodGet, target, nil)).Body.Bytes(), &stats); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if stats.Followers != 1 {
+		t.Errorf("Followers = %d, want 1", stats.Followers)
+	}
+	if calls := f.count("GET /users/octocat"); calls != 1 {
+		t.Errorf("user calls = %d, want the cached entry served as is", calls)
+	}
+}
+
+// TestSectionRefreshErrorTTLMultiplier Check that a section refreshed while GitHub recently failed is kept ErrorTTLMultiplier times longer.
+func TestSectionRefreshErrorTTLMultiplier(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	f.handleJSON("GET /users/octocat/repos", []map[string]interface{}{repoJSON("octocat", "hello", 10)})
+	f.handle("GET /users/broken", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusBadGateway, map[string]string{"message": "Bad Gateway"})
+	})
+	g := newTestGStats(t, f, Config{
+		CacheDuration:         time.Hour,
+		SectionCacheDurations: map[string]time.Duration{"stars": 10 * time.Millisecond},
+		ErrorTTLMultiplier:    1000,
+	})
+	target := "/stats?username=octocat&include_stars=true"
+
+	serveRequest(g, httptest.NewRequest(http.MethodGet, target, nil))
+	time.Sleep(20 * time.Millisecond)
+	if _, err := g.GetGitHubStats("broken", IncludeOptions{}); err == nil {
+		t.Fatal("expected an error")
+	}
+	serveRequest(g, httptest.NewRequest(http.MethodGet, target, nil))
+	if calls := f.count("GET /users/octocat/repos"); calls != 2 {
+		t.Fatalf("repository list calls = %d, want the stars refreshed", calls)
+	}
+
+	for key, entry := range g.cache.Snapshot() {
+		if !strings.Contains(key, ":octocat:") {
+			continue
+		}
+		if remaining := time.Until(entry.Sections["stars"]); remaining < 9*time.Second {
+			t.Errorf("stars section expires in %v, want about 10s", remaining)
+		}
+		return
+	}
+	t.Fatal("octocat not cached")
+}
+
+// TestSectionStarsRefreshRepos Check that a "stars" refresh also updates the stars of the cached repositories, without their details.
+func TestSectionStarsRefreshRepos(t *testing.T) {
+	f := newFakeGitHub(t)
+	var stars atomic.Int32
+	stars.Store(10)
+	f.handleUser("octocat", nil)
+	f.handle("GET /users/octocat/repos", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, []map[string]interface{}{repoJSON("octocat", "hello", int(stars.Load()))})
+	})
+	f.handleJSON("GET /repos/octocat/hello/languages", map[string]int{"Go": 100})
+	g := newTestGStats(t, f, Config{
+		CacheDuration:         time.Hour,
+		SectionCacheDurations: map[string]time.Duration{"stars": 10 * time.Millisecond},
+	})
+	get := func() GitHubStats {
+		rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat&include_stars=true&include_repos=true&include_languages=true", nil))
+		var stats GitHubStats
+		if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return stats
+	}
+
+	get()
+	stars.Store(20)
+	time.Sleep(20 * time.Millisecond)
+
+	stats := get()
+	if stats.TotalStars != 20 || len(stats.Repositories) != 1 || stats.Repositories[0].Stars != 20 {
+		t.Errorf("TotalStars = %d, Repositories = %+v, want 20 stars in both", stats.TotalStars, stats.Repositories)
+	}
+	if stats.Repositories[0].Languages["Go"] != 100 {
+		t.Errorf("Languages = %v, want the cached ones", stats.Repositories[0].Languages)
+	}
+	if calls := f.count("GET /repos/octocat/hello/languages"); calls != 1 {
+		t.Errorf("language calls = %d, want the first fetch only", calls)
+	}
+}
+
+// TestSectionCacheDurationsInvalid Check that setup rejects an unknown section or a duration that isn't positive.
+func TestSectionCacheDurationsInvalid(t *testing.T) {
+	for _, durations := range []map[string]time.Duration{
+		{"followers": time.Minute},
+		{"stars": 0},
+	} {
+		if _, err := NewGStats(Config{Token: "test-token", SectionCacheDurations: durations}); err == nil {
+			t.Errorf("NewGStats accepted SectionCacheDurations %v", durations)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package githubstats
+
+import (
+	"context"
+
+	"github.com/google/go-github/github"
+)
+
+const sponsorsQuery = `query($login: String!) {
+  user(login: $login) {
+    hasSponsorsListing
+    sponsors {
+      totalCount
+    }
+    sponsoring {
+      totalCount
+    }
+  }
+}`
+
+type SponsorsInfo struct {
+	HasSponsorsListing bool `json:"has_sponsors_listing"` // Whether the user accepts sponsorships
+	Sponsors           int  `json:"sponsors"`             // Number of sponsors of the user
+	Sponsoring         int  `json:"sponsoring"`           // Number of accounts the user sponsors
+}
+
+type sponsorsData struct {
+	User *struct {
+		HasSponsorsListing bool `json:"hasSponsorsListing"`
+		Sponsors           struct {
+			TotalCount int `json:"totalCount"`
+		} `json:"sponsors"`
+		Sponsoring struct {
+			TotalCount int `json:"totalCount"`
+		} `json:"sponsoring"`
+	} `json:"user"`
+}
+
+// fetchSponsors Fetch the GitHub Sponsors status of the user.
+/*
+ * A user without a Sponsors profile just gets zero counts.
+ *
+ * @param ctx context.Context - The context
+ * @param client *github.Client - The GitHub client
+ * @param username string - The username
+ * @return *SponsorsInfo, error - The sponsors info, the error
+ */
+func fetchSponsors(ctx context.Context, client *github.Client, username string) (*SponsorsInfo, error) {
+	var data sponsorsData
+	if err := queryGraphQL(ctx, client, sponsorsQuery, map[string]interface{}{"login": username}, &data); err != nil {
+		return nil, err
+	}
+
+	info := &SponsorsInfo{}
+	if data.User != nil {
+		info.HasSponsorsListing = data.User.HasSponsorsListing
+		info.Sponsors = data.User.Sponsors.TotalCount
+		info.Sponsoring = data.User.Sponsoring.TotalCount
+	}
+	return info, nil
+}
@@ -0,0 +1,49 @@
+package githubstats
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// TestFetchSponsors Check that the sponsors status is read from GraphQL, a user without a Sponsors profile getting zero counts.
+func TestFetchSponsors(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	f.handleUser("ghost", nil)
+	f.handle("POST /graphql", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Variables map[string]string `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Variables["login"] != "octocat" {
+			writeJSON(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{"user": nil}})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"data": map[string]interface{}{
+				"user": map[string]interface{}{
+					"hasSponsorsListing": true,
+					"sponsors":           map[string]interface{}{"totalCount": 12},
+					"sponsoring":         map[string]interface{}{"totalCount": 3},
+				},
+			},
+		})
+	})
+	g := newTestGStats(t, f, Config{})
+
+	stats, err := g.GetGitHubStats("octocat", IncludeOptions{IncludeSponsors: true})
+	if err != nil {
+		t.Fatalf("GetGitHubStats: %v", err)
+	}
+	if want := (SponsorsInfo{HasSponsorsListing: true, Sponsors: 12, Sponsoring: 3}); stats.Sponsors == nil || *stats.Sponsors != want {
+		t.Errorf("Sponsors = %+v, want %+v", stats.Sponsors, want)
+	}
+
+	stats, err = g.GetGitHubStats("ghost", IncludeOptions{IncludeSponsors: true})
+	if err != nil {
+		t.Fatalf("GetGitHubStats: %v", err)
+	}
+	if stats.Sponsors == nil || *stats.Sponsors != (SponsorsInfo{}) {
+		t.Errorf("Sponsors = %+v, want zero counts", stats.Sponsors)
+	}
+}
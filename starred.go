@@ -0,0 +1,39 @@
+package githubstats
+
+import (
+	"context"
+
+	"github.com/google/go-github/github"
+)
+
+// fetchStarredRepos Paginate the repositories starred by the user, most recently starred first, stopping at the limit.
+/*
+ * @param ctx context.Context - The context
+ * @param client *github.Client - The GitHub client
+ * @param username string - The username
+ * @param limit int - The maximum number of repositories
+ * @return []RepoStats, error - The repositories, the error
+ */
+func fetchStarredRepos(ctx context.Context, client *github.Client, username string, limit int) ([]RepoStats, error) {
+	repos := []RepoStats{}
+	listOpts := &github.ActivityListStarredOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for len(repos) < limit {
+		starred, resp, err := client.Activity.ListStarred(ctx, username, listOpts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, star := range starred {
+			if len(repos) == limit {
+				break
+			}
+			repos = append(repos, newRepoStats(star.GetRepository()))
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		listOpts.Page = resp.NextPage
+	}
+	return repos, nil
+}
@@ -0,0 +1,42 @@
+package githubstats
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestStarredRepositories Check that the starred repositories are paginated up to MaxStarredRepos.
+func TestStarredRepositories(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	f.handle("GET /users/octocat/starred", func(w http.ResponseWriter, r *http.Request) {
+		page := 1
+		fmt.Sscan(r.URL.Query().Get("page"), &page)
+		setNextPage(w, r, page+1)
+		writeJSON(w, http.StatusOK, []map[string]interface{}{
+			{"starred_at": "2026-10-01T00:00:00Z", "repo": repoJSON("golang", fmt.Sprint("go", page, "a"), 100)},
+			{"starred_at": "2026-09-01T00:00:00Z", "repo": repoJSON("golang", fmt.Sprint("go", page, "b"), 50)},
+		})
+	})
+	g := newTestGStats(t, f, Config{MaxStarredRepos: 3})
+
+	stats, err := g.GetGitHubStats("octocat", IncludeOptions{IncludeStarred: true})
+	if err != nil {
+		t.Fatalf("GetGitHubStats: %v", err)
+	}
+	var names []string
+	for _, repo := range stats.StarredRepositories {
+		names = append(names, repo.Name)
+	}
+	if got := strings.Join(names, ","); got != "golang/go1a,golang/go1b,golang/go2a" {
+		t.Errorf("StarredRepositories = %s, want golang/go1a,golang/go1b,golang/go2a", got)
+	}
+	if stats.StarredRepositories[0].Stars != 100 {
+		t.Errorf("Stars = %d, want 100", stats.StarredRepositories[0].Stars)
+	}
+	if calls := f.count("GET /users/octocat/starred"); calls != 2 {
+		t.Errorf("starred calls = %d, want 2", calls)
+	}
+}
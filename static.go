@@ -0,0 +1,29 @@
+package githubstats
+
+import "strings"
+
+// indexStaticResponses Index the canned stats by lowercase username, GitHub logins being case-insensitive.
+/*
+ * @param responses map[string]GitHubStats - The canned stats by username
+ * @return map[string]GitHubStats - The canned stats by lowercase username
+ */
+func indexStaticResponses(responses map[string]GitHubStats) map[string]GitHubStats {
+	index := make(map[string]GitHubStats, len(responses))
+	for username, stats := range responses {
+		if stats.Username == "" {
+			stats.Username = username
+		}
+		index[strings.ToLower(username)] = stats
+	}
+	return index
+}
+
+// staticStats Get the canned stats of a user from StaticResponses.
+/*
+ * @param username string - The username
+ * @return GitHubStats, bool - The stats, found
+ */
+func (g *GStats) staticStats(username string) (GitHubStats, bool) {
+	stats, found := g.staticResponses[strings.ToLower(username)]
+	return stats, found
+}
@@ -0,0 +1,29 @@
+package githubstats
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestStaticResponses Check that a canned user is served without calling GitHub, whatever the case of the login.
+func TestStaticResponses(t *testing.T) {
+	f := newFakeGitHub(t)
+	g := newTestGStats(t, f, Config{StaticResponses: map[string]GitHubStats{"Demo": {Followers: 42}}})
+
+	rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=demo&include_followers=true", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	var stats GitHubStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if stats.Username != "Demo" || stats.Followers != 42 {
+		t.Errorf("stats = %+v, want the canned ones", stats)
+	}
+	if calls := f.totalCalls(); calls != 0 {
+		t.Errorf("GitHub calls = %d, want 0", calls)
+	}
+}
@@ -0,0 +1,119 @@
+package githubstats
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+const contributionCalendarQuery = `query($login: String!) {
+  user(login: $login) {
+    contributionsCollection {
+      contributionCalendar {
+        weeks {
+          contributionDays {
+            date
+            contributionCount
+          }
+        }
+      }
+    }
+  }
+}`
+
+type contributionDay struct {
+	Date              string `json:"date"`
+	ContributionCount int    `json:"contributionCount"`
+}
+
+type contributionCalendarData struct {
+	User struct {
+		ContributionsCollection struct {
+			ContributionCalendar struct {
+				Weeks []struct {
+					ContributionDays []contributionDay `json:"contributionDays"`
+				} `json:"weeks"`
+			} `json:"contributionCalendar"`
+		} `json:"contributionsCollection"`
+	} `json:"user"`
+}
+
+// fetchStreaks Fetch the contribution calendar of the last year and compute the streaks.
+/*
+ * @param ctx context.Context - The context
+ * @param client *github.Client - The GitHub client
+ * @param username string - The username
+ * @return int, int, error - The current streak, the longest streak, the error
+ */
+func fetchStreaks(ctx context.Context, client *github.Client, username string) (int, int, error) {
+	var data contributionCalendarData
+	if err := queryGraphQL(ctx, client, contributionCalendarQuery, map[string]interface{}{"login": username}, &data); err != nil {
+		return 0, 0, err
+	}
+
+	var days []contributionDay
+	for _, week := range data.User.ContributionsCollection.ContributionCalendar.Weeks {
+		days = append(days, week.ContributionDays...)
+	}
+
+	current, longest := computeStreaks(days, time.Now().UTC())
+	return current, longest, nil
+}
+
+// computeStreaks Compute the current and longest daily contribution streaks.
+/*
+ * Days are compared as UTC dates. The current streak is not broken by an empty today, since the day is not over yet.
+ *
+ * @param days []contributionDay - The contribution days
+ * @param now time.Time - The current time
+ * @return int, int - The current streak, the longest streak
+ */
+func computeStreaks(days []contributionDay, now time.Time) (int, int) {
+	today := now.UTC().Format(time.DateOnly)
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Date < days[j].Date
+	})
+
+	longest, run := 0, 0
+	var previous time.Time
+	for _, day := range days {
+		if day.Date > today {
+			break
+		}
+		date, err := time.Parse(time.DateOnly, day.Date)
+		if err != nil {
+			continue
+		}
+
+		// A gap in the calendar breaks the streak too
+		if day.ContributionCount == 0 || (!previous.IsZero() && date.Sub(previous) > 24*time.Hour) {
+			run = 0
+		}
+		if day.ContributionCount > 0 {
+			run++
+			if run > longest {
+				longest = run
+			}
+		}
+		previous = date
+	}
+
+	// Walk back from today for the current streak
+	current := 0
+	for i := len(days) - 1; i >= 0; i-- {
+		day := days[i]
+		if day.Date > today {
+			continue
+		}
+		if day.ContributionCount == 0 {
+			if day.Date == today {
+				continue
+			}
+			break
+		}
+		current++
+	}
+	return current, longest
+}
@@ -0,0 +1,75 @@
+package githubstats
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// calendarDays Build consecutive contribution days ending today.
+/*
+ * @param now time.Time - The current time
+ * @param counts []int - The contributions of each day, oldest first
+ * @return []contributionDay - The days
+ */
+func calendarDays(now time.Time, counts []int) []contributionDay {
+	days := make([]contributionDay, len(counts))
+	for i, count := range counts {
+		date := now.AddDate(0, 0, i-len(counts)+1)
+		days[i] = contributionDay{Date: date.Format(time.DateOnly), ContributionCount: count}
+	}
+	return days
+}
+
+// TestComputeStreaks Check the current and longest streaks, an empty today not breaking the current one.
+func TestComputeStreaks(t *testing.T) {
+	now := time.Date(2024, 6, 15, 18, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name             string
+		counts           []int
+		current, longest int
+	}{
+		{"none", []int{0, 0, 0}, 0, 0},
+		{"ongoing", []int{1, 0, 2, 3, 1}, 3, 3},
+		{"empty today", []int{4, 4, 0}, 2, 2},
+		{"broken", []int{1, 1, 1, 1, 0, 1, 0, 0}, 0, 4},
+	}
+	for _, tt := range tests {
+		current, longest := computeStreaks(calendarDays(now, tt.counts), now)
+		if current != tt.current || longest != tt.longest {
+			t.Errorf("%s: streaks = %d, %d, want %d, %d", tt.name, current, longest, tt.current, tt.longest)
+		}
+	}
+}
+
+// TestFetchStreaks Check that the streaks are computed from the GraphQL contribution calendar.
+func TestFetchStreaks(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	f.handle("POST /graphql", func(w http.ResponseWriter, r *http.Request) {
+		days := calendarDays(time.Now().UTC(), []int{1, 1, 1, 0, 1, 1})
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"data": map[string]interface{}{
+				"user": map[string]interface{}{
+					"contributionsCollection": map[string]interface{}{
+						"contributionCalendar": map[string]interface{}{
+							"weeks": []map[string]interface{}{
+								{"contributionDays": days[:3]},
+								{"contributionDays": days[3:]},
+							},
+						},
+					},
+				},
+			},
+		})
+	})
+	g := newTestGStats(t, f, Config{})
+
+	stats, err := g.GetGitHubStats("octocat", IncludeOptions{IncludeStreak: true})
+	if err != nil {
+		t.Fatalf("GetGitHubStats: %v", err)
+	}
+	if stats.CurrentStreak != 2 || stats.LongestStreak != 3 {
+		t.Errorf("streaks = %d, %d, want 2, 3", stats.CurrentStreak, stats.LongestStreak)
+	}
+}
@@ -0,0 +1,118 @@
+package githubstats
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// optionParams Query parameters read by parseIncludeOptions.
+var optionParams = []string{
+	"preset",
+	"include_stars", "include_followers", "include_following", "include_follower_list", "include_following_list",
+	"include_repos", "include_first_n_repos", "include_orgs", "include_org_roles", "include_org_details", "include_readme",
+	"include_contributors", "include_languages", "include_profile_languages", "include_latest_release", "include_commit_activity", "include_issue_breakdown", "heavy_min_stars", "min_contributions", "include_external_contributions",
+	"include_streak", "include_sponsors", "include_starred", "include_activity", "include_private", "pushed_since", "top_by",
+}
+
+// validateForcedOptions Check that every forced parameter is an option with a value parseIncludeOptions understands.
+/*
+ * A wrong name or value would otherwise be silently ignored on every request.
+ *
+ * @param forced map[string]string - The forced parameters
+ * @param presets map[string]IncludeOptions - The configured presets
+ * @return error? - The error
+ */
+func validateForcedOptions(forced map[string]string, presets map[string]IncludeOptions) error {
+	known := make(map[string]bool, len(optionParams))
+	for _, name := range optionParams {
+		known[name] = true
+	}
+
+	for name, value := range forced {
+		if !known[name] {
+			return fmt.Errorf("githubstats: invalid ForceIncludeOptions parameter %q", name)
+		}
+		valid := true
+		switch name {
+		case "preset":
+			_, configured := presets[value]
+			_, builtin := defaultPresets[value]
+			valid = configured || builtin
+		case "include_first_n_repos", "heavy_min_stars", "min_contributions":
+			_, err := strconv.Atoi(value)
+			valid = err == nil
+		case "pushed_since":
+			_, err := parseTime(value)
+			valid = err == nil
+		case "top_by":
+			valid = value == "" || value == TopByStars || value == TopByScore
+		default:
+			valid = value == "true" || value == "false"
+		}
+		if !valid {
+			return fmt.Errorf("githubstats: invalid ForceIncludeOptions value %q for %q", value, name)
+		}
+	}
+	return nil
+}
+
+// Query parameters of each endpoint besides the options, format is read by every error page.
+var (
+	statsParams   = []string{"username", "user_id", "usernames", "format", "expr", "download"}
+	compareParams = []string{"a", "b", "format", "download"}
+	orgParams     = []string{"include_contributors", "format"}
+	warmParams    = []string{"format"}
+	reposParams   = []string{"format", "download"}
+)
+
+// checkStrictParams Reject the unknown query parameters with a 400 when StrictParams is set.
+/*
+ * @param w http.ResponseWriter - The response writer
+ * @param r *http.Request - The request
+ * @param withOptions bool - Whether the endpoint reads the include options
+ * @param known []string - The other parameters of the endpoint
+ * @return bool - Whether the request can go on
+ */
+func (g *GStats) checkStrictParams(w http.ResponseWriter, r *http.Request, withOptions bool, known []string) bool {
+	if !g.config.StrictParams {
+		return true
+	}
+	unknown := unknownParams(r.URL.Query(), withOptions, known)
+	if len(unknown) == 0 {
+		return true
+	}
+	writeError(w, r, "Unknown query parameters: "+strings.Join(unknown, ", "), http.StatusBadRequest)
+	return false
+}
+
+// unknownParams List the query parameters an endpoint doesn't read, sorted.
+/*
+ * @param query url.Values - The query
+ * @param withOptions bool - Whether the endpoint reads the include options
+ * @param known []string - The other parameters of the endpoint
+ * @return []string - The unknown parameters
+ */
+func unknownParams(query url.Values, withOptions bool, known []string) []string {
+	accepted := make(map[string]bool, len(optionParams)+len(known))
+	for _, name := range known {
+		accepted[name] = true
+	}
+	if withOptions {
+		for _, name := range optionParams {
+			accepted[name] = true
+		}
+	}
+
+	var unknown []string
+	for name := range query {
+		if !accepted[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
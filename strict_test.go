@@ -0,0 +1,54 @@
+package githubstats
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestStrictParams Check that an unknown query parameter is answered 400 in strict mode, and ignored otherwise.
+func TestStrictParams(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	target := "/stats?username=octocat&include_star=true&include_followers=true"
+
+	strict := newTestGStats(t, f, Config{StrictParams: true})
+	rec := serveRequest(strict, httptest.NewRequest(http.MethodGet, target, nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("strict status = %d, want 400", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "include_star") {
+		t.Errorf("body = %s, want the unknown parameter named", rec.Body)
+	}
+	if calls := f.totalCalls(); calls != 0 {
+		t.Errorf("GitHub calls = %d, want 0", calls)
+	}
+
+	lenient := newTestGStats(t, f, Config{})
+	if rec := serveRequest(lenient, httptest.NewRequest(http.MethodGet, target, nil)); rec.Code != http.StatusOK {
+		t.Errorf("lenient status = %d, want 200", rec.Code)
+	}
+}
+
+// TestForceIncludeOptionsInvalid Check that setup rejects a forced parameter that isn't an option or whose value doesn't parse.
+func TestForceIncludeOptionsInvalid(t *testing.T) {
+	for _, forced := range []map[string]string{
+		{"include_contributor": "false"},
+		{"username": "octocat"},
+		{"include_contributors": "no"},
+		{"include_first_n_repos": "ten"},
+		{"pushed_since": "yesterday"},
+		{"top_by": "forks"},
+		{"preset": "unknown"},
+	} {
+		if _, err := NewGStats(Config{Token: "test-token", ForceIncludeOptions: forced}); err == nil {
+			t.Errorf("NewGStats accepted ForceIncludeOptions %v", forced)
+		}
+	}
+
+	valid := map[string]string{"include_contributors": "false", "include_first_n_repos": "10", "pushed_since": "30d", "top_by": "stars"}
+	if _, err := NewGStats(Config{Token: "test-token", ForceIncludeOptions: valid}); err != nil {
+		t.Errorf("NewGStats rejected ForceIncludeOptions %v: %v", valid, err)
+	}
+}
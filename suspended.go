@@ -0,0 +1,29 @@
+package githubstats
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/go-github/github"
+)
+
+// ErrUserSuspended is returned when the GitHub account is suspended or was deleted, unlike ErrUserNotFound it won't come back.
+var ErrUserSuspended = errors.New("user suspended")
+
+// isSuspended Check if the user lookup means the account is suspended or gone.
+/*
+ * GitHub answers 410 for some deleted accounts, a token allowed to see suspended accounts gets the user with SuspendedAt set.
+ * A 403 with a "suspended" message is about the account of the token, not the requested one, it is a regular error.
+ *
+ * @param user *github.User - The user, nil on error
+ * @param err error - The error of the lookup
+ * @return bool - The result
+ */
+func isSuspended(user *github.User, err error) bool {
+	if err == nil {
+		return user != nil && user.SuspendedAt != nil
+	}
+
+	var errResp *github.ErrorResponse
+	return errors.As(err, &errResp) && errResp.Response != nil && errResp.Response.StatusCode == http.StatusGone
+}
@@ -0,0 +1,38 @@
+package githubstats
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestSuspendedUser Check that a suspended or deleted account is answered 410, unlike an unknown one or a suspended token.
+func TestSuspendedUser(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handle("GET /users/gone", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusGone, map[string]string{"message": "This account was deleted"})
+	})
+	f.handle("GET /users/banned", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusForbidden, map[string]string{"message": "Sorry. Your account was suspended."})
+	})
+	f.handleUser("flagged", map[string]interface{}{"suspended_at": "2026-01-01T00:00:00Z"})
+	g := newTestGStats(t, f, Config{})
+
+	for username, want := range map[string]int{
+		"gone":    http.StatusGone,
+		"banned":  http.StatusInternalServerError,
+		"flagged": http.StatusGone,
+		"ghost":   http.StatusNotFound,
+	} {
+		if rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username="+username, nil)); rec.Code != want {
+			t.Errorf("%s: status = %d, want %d", username, rec.Code, want)
+		}
+	}
+	if _, err := g.GetGitHubStats("gone", IncludeOptions{}); !errors.Is(err, ErrUserSuspended) {
+		t.Errorf("err = %v, want ErrUserSuspended", err)
+	}
+	if _, err := g.GetGitHubStats("banned", IncludeOptions{}); err == nil || errors.Is(err, ErrUserSuspended) {
+		t.Errorf("err = %v, want the 403 of the token", err)
+	}
+}
@@ -0,0 +1,118 @@
+package githubstats
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+const (
+	TimeFormatRFC3339 = "rfc3339" // 2006-01-02T15:04:05Z07:00 (default)
+	TimeFormatUnix    = "unix"    // Unix epoch seconds
+	TimeFormatUnixMs  = "unixms"  // Unix epoch milliseconds
+)
+
+// JSONTime A time serialized according to the configured TimeFormat.
+type JSONTime struct {
+	time.Time
+	format string
+}
+
+// MarshalJSON Serialize the time, a zero time is serialized as null.
+/*
+ * @return []byte, error - The JSON, the error
+ */
+func (t JSONTime) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	switch t.format {
+	case TimeFormatUnix:
+		return []byte(strconv.FormatInt(t.Unix(), 10)), nil
+	case TimeFormatUnixMs:
+		return []byte(strconv.FormatInt(t.UnixMilli(), 10)), nil
+	default:
+		return json.Marshal(t.Time.UTC().Format(time.RFC3339))
+	}
+}
+
+// UnmarshalJSON Deserialize the time from any of the supported formats.
+/*
+ * @param data []byte - The JSON
+ * @return error? - The error
+ */
+func (t *JSONTime) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		t.Time = time.Time{}
+		return nil
+	}
+	if n, err := strconv.ParseInt(string(data), 10, 64); err == nil {
+		// Seconds won't reach 1e11 before year 5138
+		if n >= 1e11 {
+			t.Time = time.UnixMilli(n).UTC()
+		} else {
+			t.Time = time.Unix(n, 0).UTC()
+		}
+		return nil
+	}
+	return json.Unmarshal(data, &t.Time)
+}
+
+// withFormat Get a copy of the time using the given format.
+/*
+ * @param format string - The format
+ * @return JSONTime - The time
+ */
+func (t JSONTime) withFormat(format string) JSONTime {
+	t.format = format
+	return t
+}
+
+// formatStats Get a copy of the stats with every time field using the configured format, ready to be serialized.
+/*
+ * The repositories are copied so the cached values are never modified.
+ *
+ * @param stats GitHubStats - The stats
+ * @return GitHubStats - The stats
+ */
+func (g *GStats) formatStats(stats GitHubStats) GitHubStats {
+	stats.Repositories = formatRepos(stats.Repositories, g.config.TimeFormat)
+	stats.ContributedRepositories = formatRepos(stats.ContributedRepositories, g.config.TimeFormat)
+	stats.StarredRepositories = formatRepos(stats.StarredRepositories, g.config.TimeFormat)
+	if stats.Activity != nil {
+		activity := make([]ActivityItem, len(stats.Activity))
+		for i, item := range stats.Activity {
+			item.CreatedAt = item.CreatedAt.withFormat(g.config.TimeFormat)
+			activity[i] = item
+		}
+		stats.Activity = activity
+	}
+	stats.omitZero = g.config.OmitZeroFields
+	return stats
+}
+
+// formatRepos Get a copy of the repositories with every time field using the given format.
+/*
+ * @param repos []RepoStats - The repositories
+ * @param format string - The format
+ * @return []RepoStats - The repositories
+ */
+func formatRepos(repos []RepoStats, format string) []RepoStats {
+	if repos == nil {
+		return nil
+	}
+	formatted := make([]RepoStats, len(repos))
+	for i, repo := range repos {
+		repo.CreatedAt = repo.CreatedAt.withFormat(format)
+		repo.UpdatedAt = repo.UpdatedAt.withFormat(format)
+		repo.PushedAt = repo.PushedAt.withFormat(format)
+		if repo.LatestRelease != nil {
+			// The release is shared with the cached value
+			release := *repo.LatestRelease
+			release.PublishedAt = release.PublishedAt.withFormat(format)
+			repo.LatestRelease = &release
+		}
+		formatted[i] = repo
+	}
+	return formatted
+}
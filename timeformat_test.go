@@ -0,0 +1,67 @@
+package githubstats
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestJSONTimeFormats Check the serialization of each TimeFormat and that every one reads back.
+func TestJSONTimeFormats(t *testing.T) {
+	at := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"", `"2024-03-01T12:30:00Z"`},
+		{TimeFormatRFC3339, `"2024-03-01T12:30:00Z"`},
+		{TimeFormatUnix, "1709296200"},
+		{TimeFormatUnixMs, "1709296200000"},
+	}
+	for _, tt := range tests {
+		data, err := json.Marshal(JSONTime{Time: at}.withFormat(tt.format))
+		if err != nil {
+			t.Fatalf("%q: Marshal: %v", tt.format, err)
+		}
+		if string(data) != tt.want {
+			t.Errorf("%q: Marshal = %s, want %s", tt.format, data, tt.want)
+		}
+
+		var back JSONTime
+		if err := json.Unmarshal(data, &back); err != nil || !back.Equal(at) {
+			t.Errorf("%q: Unmarshal = %v, %v, want %v", tt.format, back.Time, err, at)
+		}
+	}
+
+	if data, _ := json.Marshal(JSONTime{}); string(data) != "null" {
+		t.Errorf("zero time = %s, want null", data)
+	}
+}
+
+// TestTimeFormatResponse Check that the repository timestamps of a response use the configured TimeFormat.
+func TestTimeFormatResponse(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleUser("octocat", nil)
+	repo := repoJSON("octocat", "hello", 1)
+	repo["created_at"] = "2024-03-01T12:30:00Z"
+	f.handleJSON("GET /users/octocat/repos", []map[string]interface{}{repo})
+	g := newTestGStats(t, f, Config{TimeFormat: TimeFormatUnix})
+
+	rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=octocat&include_repos=true", nil))
+	if !strings.Contains(rec.Body.String(), `"created_at":1709296200`) {
+		t.Errorf("body = %s, want created_at in Unix seconds", rec.Body)
+	}
+	if !strings.Contains(rec.Body.String(), `"pushed_at":null`) {
+		t.Errorf("body = %s, want a null pushed_at", rec.Body)
+	}
+}
+
+// TestTimeFormatInvalid Check that an unknown TimeFormat is rejected instead of falling back to RFC 3339.
+func TestTimeFormatInvalid(t *testing.T) {
+	if _, err := NewGStats(Config{Token: "test-token", TimeFormat: "unix_ms"}); err == nil {
+		t.Error("NewGStats accepted an unknown TimeFormat")
+	}
+}
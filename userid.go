@@ -0,0 +1,54 @@
+package githubstats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/google/go-github/github"
+)
+
+// ErrInvalidUserID is returned when the user_id query parameter isn't a positive integer.
+var ErrInvalidUserID = errors.New("user_id must be a positive integer")
+
+// resolveUserID Resolve a numeric GitHub user ID, stable across renames, to the current login.
+/*
+ * The login is cached for CacheDuration, a rename shows up once it expires.
+ *
+ * @param ctx context.Context - The context
+ * @param id string - The user ID
+ * @return string, error - The login, the error
+ */
+func (g *GStats) resolveUserID(ctx context.Context, id string) (string, error) {
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || n <= 0 {
+		return "", ErrInvalidUserID
+	}
+
+	key := g.config.CacheKeyPrefix + cacheSchemaVersion + ":user_id:" + strconv.FormatInt(n, 10)
+	if !g.config.DisableCache {
+		if cached, found := g.cache.Get(key); found {
+			return cached.Username, nil
+		}
+	}
+
+	var user *github.User
+	err = g.guardedCall(ctx, func() error {
+		userCtx, cancel := withCallTimeout(ctx, g.config.UserTimeout)
+		defer cancel()
+		var err error
+		user, _, err = g.clientFor(ctx).Users.GetByID(userCtx, n)
+		return err
+	})
+	if isNotFound(err) {
+		return "", fmt.Errorf("%w: %w", ErrUserNotFound, err)
+	}
+	if err != nil {
+		return "", err
+	}
+	if !g.config.DisableCache {
+		g.cache.Set(key, GitHubStats{Username: user.GetLogin()}, g.config.CacheDuration)
+	}
+	return user.GetLogin(), nil
+}
@@ -0,0 +1,62 @@
+package githubstats
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestUserID Check that a numeric user_id resolves to the stats of its current login.
+func TestUserID(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleJSON("GET /user/583231", map[string]interface{}{"login": "octocat", "id": 583231})
+	f.handleUser("octocat", map[string]interface{}{"followers": 9})
+	g := newTestGStats(t, f, Config{})
+
+	rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?user_id=583231&include_followers=true", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	var stats GitHubStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if stats.Username != "octocat" || stats.Followers != 9 {
+		t.Errorf("stats = %+v, want the stats of octocat", stats)
+	}
+
+	for target, want := range map[string]int{
+		"/stats?user_id=octocat": http.StatusBadRequest,
+		"/stats?user_id=-1":      http.StatusBadRequest,
+		"/stats?user_id=42":      http.StatusNotFound,
+	} {
+		if rec := serveRequest(g, httptest.NewRequest(http.MethodGet, target, nil)); rec.Code != want {
+			t.Errorf("%s: status = %d, want %d", target, rec.Code, want)
+		}
+	}
+}
+
+// TestUserIDCached Check that the options are validated before resolving the ID, and that the login of an ID is cached.
+func TestUserIDCached(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handleJSON("GET /user/583231", map[string]interface{}{"login": "octocat", "id": 583231})
+	f.handleUser("octocat", map[string]interface{}{"followers": 9})
+	g := newTestGStats(t, f, Config{})
+
+	if rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?user_id=583231&include_contributors=true", nil)); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("invalid options: status = %d, want 422", rec.Code)
+	}
+	if calls := f.totalCalls(); calls != 0 {
+		t.Errorf("GitHub calls for invalid options = %d, want 0", calls)
+	}
+
+	for _, target := range []string{"/stats?user_id=583231&include_followers=true", "/stats?user_id=583231&include_following=true"} {
+		if rec := serveRequest(g, httptest.NewRequest(http.MethodGet, target, nil)); rec.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d", target, rec.Code)
+		}
+	}
+	if calls := f.count("GET /user/583231"); calls != 1 {
+		t.Errorf("ID lookups = %d, want 1", calls)
+	}
+}
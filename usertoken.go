@@ -0,0 +1,104 @@
+package githubstats
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/github"
+	"golang.org/x/oauth2"
+)
+
+// meUsername Username resolved to the owner of the caller's token.
+const meUsername = "@me"
+
+// ErrUserTokenRequired is returned when @me is requested without a user token.
+var ErrUserTokenRequired = errors.New("a GitHub token is required to use @me")
+
+// ErrInvalidUserToken is returned when GitHub rejects the caller's token.
+var ErrInvalidUserToken = errors.New("the GitHub token was rejected")
+
+type userClientKey struct{}
+
+// withUserClient Attach a GitHub client built from the caller's bearer token to the request context.
+/*
+ * The request is left untouched when AllowUserTokens is disabled or there is no bearer token.
+ *
+ * @param r *http.Request - The request
+ * @return *http.Request - The request
+ */
+func (g *GStats) withUserClient(r *http.Request) *http.Request {
+	if !g.config.AllowUserTokens {
+		return r
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return r
+	}
+	client := newGitHubClient(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}), g.config, g.transport).client
+	return r.WithContext(context.WithValue(r.Context(), userClientKey{}, client))
+}
+
+// userClient Get the client built from the caller's token.
+/*
+ * @param ctx context.Context - The context
+ * @return *github.Client - The client, nil if the caller didn't send a token
+ */
+func userClient(ctx context.Context) *github.Client {
+	client, _ := ctx.Value(userClientKey{}).(*github.Client)
+	return client
+}
+
+// clientFor Get the client to use for a request, preferring the caller's token.
+/*
+ * @param ctx context.Context - The context
+ * @return *github.Client - The client
+ */
+func (g *GStats) clientFor(ctx context.Context) *github.Client {
+	if client := userClient(ctx); client != nil {
+		return client
+	}
+	return g.clients.pick()
+}
+
+// isTokenOwner Check if the token of the client belongs to the user.
+/*
+ * @param ctx context.Context - The context
+ * @param client *github.Client - The GitHub client
+ * @param username string - The username
+ * @return bool, error - The result, the error
+ */
+func isTokenOwner(ctx context.Context, client *github.Client, username string) (bool, error) {
+	user, _, err := client.Users.Get(ctx, "")
+	if err != nil {
+		return false, err
+	}
+	return strings.EqualFold(user.GetLogin(), username), nil
+}
+
+// resolveUsername Resolve @me to the login of the caller's token owner.
+/*
+ * @param ctx context.Context - The context
+ * @param username string - The username
+ * @return string, error - The username, the error
+ */
+func resolveUsername(ctx context.Context, username string) (string, error) {
+	if username != meUsername {
+		return username, nil
+	}
+
+	client := userClient(ctx)
+	if client == nil {
+		return "", ErrUserTokenRequired
+	}
+	user, _, err := client.Users.Get(ctx, "")
+	if err != nil {
+		var errResp *github.ErrorResponse
+		if errors.As(err, &errResp) && errResp.Response != nil && errResp.Response.StatusCode == http.StatusUnauthorized {
+			return "", ErrInvalidUserToken
+		}
+		return "", err
+	}
+	return user.GetLogin(), nil
+}
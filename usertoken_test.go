@@ -0,0 +1,49 @@
+package githubstats
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestResolveMe Check that @me resolves to the owner of the caller's token.
+func TestResolveMe(t *testing.T) {
+	f := newFakeGitHub(t)
+	f.handle("GET /user", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer caller-token" {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"message": "Bad credentials"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"login": "octocat"})
+	})
+	g := newTestGStats(t, f, Config{AllowUserTokens: true})
+
+	ctx := context.WithValue(context.Background(), userClientKey{}, f.userClient(g, "caller-token"))
+	if username, err := resolveUsername(ctx, "@me"); err != nil || username != "octocat" {
+		t.Errorf("resolveUsername = %q, %v, want octocat", username, err)
+	}
+	if username, err := resolveUsername(ctx, "alice"); err != nil || username != "alice" {
+		t.Errorf("resolveUsername of a login = %q, %v, want it unchanged", username, err)
+	}
+
+	ctx = context.WithValue(context.Background(), userClientKey{}, f.userClient(g, "revoked"))
+	if _, err := resolveUsername(ctx, "@me"); !errors.Is(err, ErrInvalidUserToken) {
+		t.Errorf("err with a rejected token = %v, want ErrInvalidUserToken", err)
+	}
+}
+
+// TestResolveMeWithoutToken Check that @me without a caller's token is answered 401.
+func TestResolveMeWithoutToken(t *testing.T) {
+	f := newFakeGitHub(t)
+	g := newTestGStats(t, f, Config{AllowUserTokens: true})
+
+	rec := serveRequest(g, httptest.NewRequest(http.MethodGet, "/stats?username=@me", nil))
+	if rec.Code != http.StatusUnauthorized || rec.Header().Get("WWW-Authenticate") != "Bearer" {
+		t.Errorf("status = %d, WWW-Authenticate = %q, want 401 Bearer", rec.Code, rec.Header().Get("WWW-Authenticate"))
+	}
+	if calls := f.totalCalls(); calls != 0 {
+		t.Errorf("GitHub calls = %d, want 0", calls)
+	}
+}